@@ -0,0 +1,131 @@
+package borm
+
+import (
+	"reflect"
+	"time"
+	"unsafe"
+
+	"github.com/gogo/protobuf/types"
+	"github.com/pkg/errors"
+)
+
+//rowAccessor
+//reads and writes the fields of the rows of a table through reflect with the field
+//indexes resolved at CreateTable, rows of any other type are rejected, the Id is
+//accessed at its precomputed offset once the row type is checked
+type rowAccessor struct {
+	rowType reflect.Type
+	//the type word of the rows as an interface, a pointer comparison is cheaper than reflect.Type
+	typeWord unsafe.Pointer
+	tableId  uint32
+	idOffset uintptr
+}
+
+func newRowAccessor(tableId uint32, rowType reflect.Type) *rowAccessor {
+	var idOffset uintptr
+	if rowType.Implements(structRowType) {
+		idOffset = rowType.Elem().Field(0).Offset
+	}
+	idOffset += rowStructType(rowType).Field(0).Offset
+	typeWord, _ := interfaceWords(reflect.Zero(rowType).Interface())
+	return &rowAccessor{rowType: rowType, typeWord: typeWord, tableId: tableId, idOffset: idOffset}
+}
+
+//interfaceWords
+//the type and data words of v
+func interfaceWords(v any) (unsafe.Pointer, unsafe.Pointer) {
+	words := (*[2]unsafe.Pointer)(unsafe.Pointer(&v))
+	return words[0], words[1]
+}
+
+//check
+//the struct of row when it is a non nil pointer to the registered struct
+func (a *rowAccessor) check(row IRow) (reflect.Value, error) {
+	if _, err := a.idPtr(row); err != nil {
+		return reflect.Value{}, err
+	}
+	return rowStruct(row), nil
+}
+
+//idPtr
+//the Id field of row when it is a non nil pointer to the registered struct
+func (a *rowAccessor) idPtr(row IRow) (*uint64, error) {
+	typeWord, p := interfaceWords(row)
+	if typeWord != a.typeWord {
+		return nil, errors.Wrapf(ErrRowTypeMismatch, "%T is not %v", row, a.rowType)
+	}
+	if p == nil {
+		return nil, errors.Wrapf(ErrRowTypeMismatch, "nil %v", a.rowType)
+	}
+	return (*uint64)(unsafe.Add(p, a.idOffset)), nil
+}
+
+func (a *rowAccessor) getId(row IRow) (uint64, error) {
+	id, err := a.idPtr(row)
+	if err != nil {
+		return 0, err
+	}
+	return *id, nil
+}
+
+func (a *rowAccessor) setId(row IRow, id uint64) error {
+	idPtr, err := a.idPtr(row)
+	if err != nil {
+		return err
+	}
+	*idPtr = id
+	return nil
+}
+
+//rowId
+//the Id of a row already known to be of its table type, like the rows decoded by a query
+func rowId(row IRow) uint64 {
+	return rowStruct(row).Field(0).Uint()
+}
+
+//fieldValue
+//the canonical index value of the struct field v, named types like protobuf
+//enums are read as their underlying type
+func (tag *tag) fieldValue(v reflect.Value) any {
+	switch tag.fieldType {
+	case String:
+		return v.String()
+	case Int:
+		return int(v.Int())
+	case Int8:
+		return int8(v.Int())
+	case Int16:
+		return int16(v.Int())
+	case Int32, Rune:
+		return int32(v.Int())
+	case Int64:
+		return v.Int()
+	case Uint:
+		return uint(v.Uint())
+	case Uint8, Byte:
+		return uint8(v.Uint())
+	case Uint16:
+		return uint16(v.Uint())
+	case Uint32:
+		return uint32(v.Uint())
+	case Uint64:
+		return v.Uint()
+	case Float32:
+		return float32(v.Float())
+	case Float64:
+		return v.Float()
+	case Complex64:
+		return complex64(v.Complex())
+	case Complex128:
+		return v.Complex()
+	case Bool:
+		return v.Bool()
+	case Bytes:
+		return v.Bytes()
+	case Time:
+		return v.Interface().(time.Time).UTC()
+	case Timestamp:
+		return timestampToTime(v.Interface().(*types.Timestamp))
+	}
+	return nil
+}
@@ -5,8 +5,7 @@ import (
 	"strings"
 )
 
-func queryAnalyzer[T IRow](c *BaseCompoundCondition[T]) string {
-	tableName := c.row.GetTableName()
+func queryAnalyzer[T IRow](c *BaseCompoundCondition[T], tableName string) string {
 	sql := fmt.Sprintf("SELECT * FROM %s WHERE ", tableName)
 
 	for _, key := range c.fieldValueMap.Keys() {
@@ -16,6 +15,12 @@ func queryAnalyzer[T IRow](c *BaseCompoundCondition[T]) string {
 	for _, v := range c.inFilterConditions {
 		sql += fmt.Sprintf("(%s) IN (%v) AND ", strings.Join(v.fieldNames, ","), v.values)
 	}
+	for _, v := range c.prefixConditions {
+		sql += fmt.Sprintf("%s LIKE '%s%%' AND ", v.fieldName, v.prefix)
+	}
+	for _, v := range c.matchConditions {
+		sql += fmt.Sprintf("MATCH(%s) AGAINST('%s') AND ", v.fieldName, v.query)
+	}
 
 	sql = strings.TrimRight(sql, "AND ")
 
@@ -24,7 +29,9 @@ func queryAnalyzer[T IRow](c *BaseCompoundCondition[T]) string {
 		order = "DESC"
 	}
 
-	if len(c.sortKey) > 0 {
+	if c.relevance {
+		sql += " ORDER BY relevance DESC"
+	} else if len(c.sortKey) > 0 {
 		sql += fmt.Sprintf(" ORDER BY %s %s", strings.Join(c.sortKey, ","), order)
 	} else {
 		sql += fmt.Sprintf(" ORDER BY id %s", order)
@@ -36,8 +43,7 @@ func queryAnalyzer[T IRow](c *BaseCompoundCondition[T]) string {
 	return sql
 }
 
-func countAnalyzer[T IRow](c *BaseCompoundCondition[T]) string {
-	tableName := c.row.GetTableName()
+func countAnalyzer[T IRow](c *BaseCompoundCondition[T], tableName string) string {
 	sql := fmt.Sprintf("SELECT COUNT(id) FROM %s WHERE ", tableName)
 
 	for _, key := range c.fieldValueMap.Keys() {
@@ -47,9 +53,23 @@ func countAnalyzer[T IRow](c *BaseCompoundCondition[T]) string {
 	for _, v := range c.inFilterConditions {
 		sql += fmt.Sprintf("(%s) IN (%v) AND ", strings.Join(v.fieldNames, ","), v.values)
 	}
+	for _, v := range c.prefixConditions {
+		sql += fmt.Sprintf("%s LIKE '%s%%' AND ", v.fieldName, v.prefix)
+	}
+	for _, v := range c.matchConditions {
+		sql += fmt.Sprintf("MATCH(%s) AGAINST('%s') AND ", v.fieldName, v.query)
+	}
 	sql = strings.TrimRight(sql, "AND ")
 	if c.limit > 0 || c.offset > 0 {
 		sql += fmt.Sprintf(" LIMIT(%v,%v)", c.offset, c.limit)
 	}
 	return sql
 }
+
+func joinAnalyzer(leftTableName, rightTableName string, fields []joinField, strategy JoinStrategy) string {
+	on := make([]string, len(fields))
+	for i, field := range fields {
+		on[i] = fmt.Sprintf("%s.%s=%s.%s", leftTableName, field.left, rightTableName, field.right)
+	}
+	return fmt.Sprintf("JOIN %s ON %s USING %s", rightTableName, strings.Join(on, " AND "), strategy)
+}
@@ -196,7 +196,53 @@ func BenchmarkJointQuery(b *testing.B) {
 }
 
 func BenchmarkGetSetRowID(b *testing.B) {
+	db, err := borm.New()
+	if err != nil {
+		b.Fatal(err)
+	}
+	err = db.CreateTable(&pb.Person{})
+	if err != nil {
+		b.Fatal(err)
+	}
+	person := &pb.Person{
+		Name:     "jacky",
+		Phone:    fmt.Sprintf("+861357546%d", 1),
+		Age:      uint32(30),
+		BirthDay: 19901111,
+		Gender:   pb.Gender_men,
+	}
+	b.Run("unsafe", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			common.SetUint64(person, uint64(i))
+			if common.GetUint64(person) != uint64(i) {
+				b.Fatal()
+			}
+		}
+	})
+	b.Run("accessor", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			err := db.SetRowId(person, uint64(i))
+			if err != nil {
+				b.Fatal(err)
+			}
+			id, err := db.GetRowId(person)
+			if err != nil || id != uint64(i) {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGetFieldVal(b *testing.B) {
 	b.StopTimer()
+	db, err := borm.New()
+	if err != nil {
+		b.Fatal(err)
+	}
+	err = db.CreateTable(&pb.Person{})
+	if err != nil {
+		b.Fatal(err)
+	}
 	person := &pb.Person{
 		Name:     "jacky",
 		Phone:    fmt.Sprintf("+861357546%d", 1),
@@ -206,10 +252,9 @@ func BenchmarkGetSetRowID(b *testing.B) {
 	}
 	b.StartTimer()
 	for i := 0; i < b.N; i++ {
-		common.SetUint64(person, uint64(i))
-		id := common.GetUint64(person)
-		if id != uint64(i) {
-			b.Fatal()
+		age, err := db.GetFieldValWithFieldName(person, "Age")
+		if err != nil || age != uint32(30) {
+			b.Fatal(err)
 		}
 	}
 }
@@ -2,19 +2,22 @@ package borm
 
 import (
 	"bytes"
-	"fmt"
-	"sort"
+	"reflect"
 	"strconv"
-	"unsafe"
+	"time"
 
 	"github.com/longbridgeapp/borm/common"
 
 	badger "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
 )
 
 type BormDb struct {
 	db           *badger.DB
 	tableManager *TableManager
+	snapshots    *snapshotManager
+	changes      *changeHub
+	triggers     *triggerManager
 
 	optConfig *Options
 }
@@ -24,10 +27,20 @@ type TableDetails struct {
 	UnionIndexCount uint64
 	NormalIndex     map[string]uint64
 	UniqueIndex     map[string]uint64
+	//entries of each union index by name, UnionIndexCount is their sum
+	UnionIndex map[string]uint64
+	//term entries of each fulltext index by field name
+	FulltextIndex map[string]uint64
 }
 
 func New(opts ...Option) (*BormDb, error) {
 	optConfig := newOptions(opts...)
+	if optConfig.IndexBuildBatchSize <= 0 {
+		return nil, errors.Wrapf(ErrOptionIllegal, "IndexBuildBatchSize %v", optConfig.IndexBuildBatchSize)
+	}
+	if optConfig.SubscribeBufferSize <= 0 {
+		return nil, errors.Wrapf(ErrOptionIllegal, "SubscribeBufferSize %v", optConfig.SubscribeBufferSize)
+	}
 	badgerConfig := badger.DefaultOptions("")
 	badgerConfig = badgerConfig.WithInMemory(true)
 	badgerConfig = badgerConfig.WithMemTableSize(optConfig.MemTableSize)
@@ -51,6 +64,9 @@ func New(opts ...Option) (*BormDb, error) {
 		optConfig:    optConfig,
 		db:           db,
 		tableManager: newTableManager(),
+		snapshots:    newSnapshotManager(optConfig.SnapshotRetention),
+		changes:      newChangeHub(optConfig.SubscribeBufferSize),
+		triggers:     newTriggerManager(),
 	}, nil
 }
 
@@ -60,21 +76,33 @@ func (bormDb *BormDb) Begin(update bool) *badger.Txn {
 
 func (bormDb *BormDb) Commit(tx *badger.Txn) error {
 	defer tx.Discard()
-	return tx.Commit()
+	return bormDb.changes.commit(tx)
 }
 
 func (bormDb *BormDb) Discard(tx *badger.Txn) {
+	bormDb.changes.discard(tx)
 	tx.Discard()
 }
 
+//update
+//like badger.DB.Update, but publish the change events of the txn after commit
+func (bormDb *BormDb) update(fn func(txn *badger.Txn) error) error {
+	txn := bormDb.Begin(true)
+	defer bormDb.Discard(txn)
+	if err := fn(txn); err != nil {
+		return err
+	}
+	return bormDb.changes.commit(txn)
+}
+
 //CreateTable
-func (bormDb *BormDb) CreateTable(row IRow) error {
-	return bormDb.tableManager.CreateTable(row, bormDb.db)
+func (bormDb *BormDb) CreateTable(row IRow, opts ...TableOption) error {
+	return bormDb.tableManager.CreateTable(row, bormDb.db, opts...)
 }
 
 //Single Insert
 func (bormDb *BormDb) Insert(row IRow) error {
-	err := bormDb.db.Update(func(txn *badger.Txn) error {
+	err := bormDb.update(func(txn *badger.Txn) error {
 		return bormDb.TxInsert(txn, row)
 	})
 	if err == badger.ErrConflict {
@@ -84,33 +112,176 @@ func (bormDb *BormDb) Insert(row IRow) error {
 	return err
 }
 
+//InsertWithTTL
+//the row and its index entries expire after ttl, overrides the table ttl
+func (bormDb *BormDb) InsertWithTTL(row IRow, ttl time.Duration) error {
+	err := bormDb.update(func(txn *badger.Txn) error {
+		return bormDb.TxInsertWithTTL(txn, row, ttl)
+	})
+	if err == badger.ErrConflict {
+		bormDb.optConfig.Logger.Warningf("Txn Insert conflict, [%+v]\n", row)
+		return bormDb.InsertWithTTL(row, ttl)
+	}
+	return err
+}
+
+//InsertWithId
+//insert the row under its own Id instead of a generated one, e.g. an upstream order id
+//or a re-imported row, ErrRowIdRepeat if the table already has a row with the Id, later
+//ids of the table sequence skip the Id
+func (bormDb *BormDb) InsertWithId(row IRow) error {
+	err := bormDb.update(func(txn *badger.Txn) error {
+		return bormDb.TxInsertWithId(txn, row)
+	})
+	if err == badger.ErrConflict {
+		bormDb.optConfig.Logger.Warningf("Txn Insert conflict, [%+v]\n", row)
+		return bormDb.InsertWithId(row)
+	}
+	return err
+}
+
 func (bormDb *BormDb) TxInsert(txn *badger.Txn, row IRow) error {
-	tableName := row.GetTableName()
-	id, err := bormDb.tableManager.GetTableId(tableName)
+	return bormDb.txInsert(txn, row, 0, false)
+}
+
+func (bormDb *BormDb) TxInsertWithTTL(txn *badger.Txn, row IRow, ttl time.Duration) error {
+	return bormDb.txInsert(txn, row, ttl, false)
+}
+
+func (bormDb *BormDb) TxInsertWithId(txn *badger.Txn, row IRow) error {
+	return bormDb.txInsert(txn, row, 0, true)
+}
+
+//nextFreeId
+//the next id of the table, ids of the sequence taken by rows inserted with their own Id
+//are skipped, ids of a custom generator are returned as is
+func (bormDb *BormDb) nextFreeId(txn *badger.Txn, tableId uint32) (uint64, error) {
+	for {
+		next, err := bormDb.tableManager.NextWithTxn(txn, tableId)
+		if err != nil || bormDb.tableManager.GetTableOptions(tableId).idGenerator != nil {
+			return next, err
+		}
+		_, err = txn.Get(encodePKey(tableId, next))
+		if err == badger.ErrKeyNotFound {
+			return next, nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+}
+
+//checkRow
+//the check constraints and the foreign keys of an inserted row
+func (bormDb *BormDb) checkRow(txn *badger.Txn, tableId uint32, tableName string, row IRow) error {
+	err := bormDb.tableManager.checkConstraints(tableId, tableName, row)
 	if err != nil {
 		return err
 	}
+	return bormDb.checkForeignKeys(txn, tableId, row)
+}
 
-	next, err := bormDb.tableManager.Next(id)
+//txInsert
+//insert the row under its own non zero Id when withId, else under the next id of the table
+func (bormDb *BormDb) txInsert(txn *badger.Txn, row IRow, ttl time.Duration, withId bool) error {
+	tableName := bormDb.tableManager.GetTableName(row)
+	id, err := bormDb.tableManager.GetTableId(tableName)
+	if err != nil {
+		return err
+	}
+	err = readSchema(txn, id)
+	if err != nil {
+		return err
+	}
+	if ttl <= 0 {
+		ttl = bormDb.tableManager.GetTableOptions(id).ttl
+	}
+	expiresAt := uint64(0)
+	if ttl > 0 {
+		expiresAt = expiresAtOf(time.Now().Add(ttl))
+	}
+	accessor, err := bormDb.tableManager.GetRowAccessor(id)
+	if err != nil {
+		return err
+	}
+	//check the row before burning an id
+	if _, err := accessor.check(row); err != nil {
+		return err
+	}
+	err = bormDb.checkRow(txn, id, tableName, row)
 	if err != nil {
 		return err
 	}
-	common.SetUint64(row, next)
-	bs, err := row.Marshal()
+	var next uint64
+	if withId {
+		next, err = accessor.getId(row)
+		if err != nil {
+			return err
+		}
+		if next == 0 {
+			return ErrRowIdIllegal
+		}
+		err = bormDb.tableManager.SkipWithTxn(txn, id, next)
+		if err != nil {
+			return err
+		}
+	} else {
+		next, err = bormDb.nextFreeId(txn, id)
+		if err != nil {
+			return err
+		}
+		err = accessor.setId(row, next)
+		if err != nil {
+			return err
+		}
+	}
+	//ids of the caller or of a custom generator may collide with existing rows
+	if _, err := txn.Get(encodePKey(id, next)); err == nil {
+		return ErrRowIdRepeat
+	} else if err != badger.ErrKeyNotFound {
+		return err
+	}
+	err = callBeforeInsert(txn, row)
+	if err != nil {
+		return err
+	}
+	if _, ok := rowTarget(row).(BeforeInsertHook); ok {
+		//the hook may have changed the row
+		err = bormDb.checkRow(txn, id, tableName, row)
+		if err != nil {
+			return err
+		}
+	}
+	bs, err := bormDb.tableManager.Marshal(id, row)
 	if err != nil {
 		return err
 	}
 
-	err = txn.Set(encodePKey(id, next), bs)
+	err = setEntry(txn, encodePKey(id, next), bs, expiresAt)
+	if err != nil {
+		return err
+	}
+	err = bormDb.createIndex(id, row, txn, next, expiresAt)
+	if err != nil {
+		return err
+	}
+	err = callAfterInsert(txn, row)
 	if err != nil {
 		return err
 	}
-	return bormDb.createIndex(id, row, txn, next)
+	if bormDb.changes.watching(id) {
+		after, err := bormDb.tableManager.Unmarshal(id, row, bs)
+		if err != nil {
+			return err
+		}
+		bormDb.changes.record(txn, id, &ChangeEvent{Type: ChangeInsert, TableName: tableName, RowId: next, After: after})
+	}
+	return bormDb.fireTriggers(txn, id, nil, row)
 }
 
 //BatchInsert
 func (bormDb *BormDb) BatchInsert(rows []IRow) error {
-	err := bormDb.db.Update(func(txn *badger.Txn) error {
+	err := bormDb.update(func(txn *badger.Txn) error {
 		// return bormDb.TxBatchInsert(txn, rows)
 		for i := 0; i < len(rows); i++ {
 			err := bormDb.TxInsert(txn, rows[i])
@@ -138,7 +309,7 @@ func (bormDb *BormDb) TxBatchInsert(txn *badger.Txn, rows []IRow) error {
 }
 
 func (bormDb *BormDb) Delete(rowId uint64, row IRow) error {
-	err := bormDb.db.Update(func(txn *badger.Txn) error {
+	err := bormDb.update(func(txn *badger.Txn) error {
 		return bormDb.TxDelete(txn, rowId, row)
 	})
 	if err == badger.ErrConflict {
@@ -149,33 +320,61 @@ func (bormDb *BormDb) Delete(rowId uint64, row IRow) error {
 }
 
 func (bormDb *BormDb) TxDelete(tx *badger.Txn, rowId uint64, row IRow) error {
-	tableName := row.GetTableName()
+	tableName := bormDb.tableManager.GetTableName(row)
 	tableId, err := bormDb.tableManager.GetTableId(tableName)
 	if err != nil {
 		return err
 	}
+	err = bormDb.tableManager.CheckRow(tableId, row)
+	if err != nil {
+		return err
+	}
+	err = readSchema(tx, tableId)
+	if err != nil {
+		return err
+	}
 	pk := encodePKey(tableId, rowId)
 	item, err := tx.Get(pk)
 	if err != nil {
 		return err
 	}
-	tpl := row.Clone().(IRow)
+	var tpl IRow
 	err = item.Value(func(val []byte) error {
-		return tpl.Unmarshal(val)
+		tpl, err = bormDb.tableManager.Unmarshal(tableId, row, val)
+		return err
 	})
 	if err != nil {
 		return err
 	}
+	err = callBeforeDelete(tx, tpl)
+	if err != nil {
+		return err
+	}
+	err = bormDb.deleteReferences(tx, tableId, tpl)
+	if err != nil {
+		return err
+	}
 	err = tx.Delete(pk)
 	if err != nil {
 		return err
 	}
-	return bormDb.deleteIndex(tableId, tpl, tx)
+	err = bormDb.deleteIndex(tableId, tpl, tx)
+	if err != nil {
+		return err
+	}
+	err = callAfterDelete(tx, tpl)
+	if err != nil {
+		return err
+	}
+	if bormDb.changes.watching(tableId) {
+		bormDb.changes.record(tx, tableId, &ChangeEvent{Type: ChangeDelete, TableName: tableName, RowId: rowId, Before: tpl})
+	}
+	return bormDb.fireTriggers(tx, tableId, tpl, nil)
 }
 
 //Update
 func (bormDb *BormDb) Update(rowId uint64, newRow IRow) error {
-	err := bormDb.db.Update(func(txn *badger.Txn) error {
+	err := bormDb.update(func(txn *badger.Txn) error {
 		return bormDb.TxUpdate(txn, rowId, newRow)
 	})
 	if err == badger.ErrConflict {
@@ -186,70 +385,220 @@ func (bormDb *BormDb) Update(rowId uint64, newRow IRow) error {
 }
 
 func (bormDb *BormDb) TxUpdate(tx *badger.Txn, rowId uint64, newRow IRow) error {
-	tableName := newRow.GetTableName()
+	tableName := bormDb.tableManager.GetTableName(newRow)
 	tableId, err := bormDb.tableManager.GetTableId(tableName)
 	if err != nil {
 		return err
 	}
+	accessor, err := bormDb.tableManager.GetRowAccessor(tableId)
+	if err != nil {
+		return err
+	}
+	if _, err := accessor.check(newRow); err != nil {
+		return err
+	}
+	err = readSchema(tx, tableId)
+	if err != nil {
+		return err
+	}
 	pk := encodePKey(tableId, rowId)
 	item, err := tx.Get(pk)
 	if err != nil {
 		return err
 	}
-	tpl := newRow.Clone().(IRow)
+	var tpl IRow
 	err = item.Value(func(val []byte) error {
-		return tpl.Unmarshal(val)
+		tpl, err = bormDb.tableManager.Unmarshal(tableId, newRow, val)
+		return err
 	})
 	if err != nil {
 		return err
 	}
+	err = accessor.setId(newRow, rowId)
+	if err != nil {
+		return err
+	}
+	err = callBeforeUpdate(tx, newRow)
+	if err != nil {
+		return err
+	}
+	err = bormDb.tableManager.checkConstraints(tableId, tableName, newRow)
+	if err != nil {
+		return err
+	}
+	err = bormDb.checkForeignKeys(tx, tableId, newRow)
+	if err != nil {
+		return err
+	}
+	err = bormDb.checkReferencedUpdate(tx, tableId, tpl, newRow)
+	if err != nil {
+		return err
+	}
 	err = bormDb.deleteIndex(tableId, tpl, tx)
 	if err != nil {
 		return err
 	}
 
-	err = bormDb.createIndex(tableId, newRow, tx, rowId)
+	//keep the expiry of the original insert
+	expiresAt := item.ExpiresAt()
+	err = bormDb.createIndex(tableId, newRow, tx, rowId, expiresAt)
+	if err != nil {
+		return err
+	}
+	bs, err := bormDb.tableManager.Marshal(tableId, newRow)
 	if err != nil {
 		return err
 	}
-	common.SetUint64(newRow, rowId)
-	bs, err := newRow.Marshal()
+	err = setEntry(tx, pk, bs, expiresAt)
 	if err != nil {
 		return err
 	}
-	return tx.Set(pk, bs)
+	err = callAfterUpdate(tx, newRow)
+	if err != nil {
+		return err
+	}
+	if bormDb.changes.watching(tableId) {
+		after, err := bormDb.tableManager.Unmarshal(tableId, newRow, bs)
+		if err != nil {
+			return err
+		}
+		bormDb.changes.record(tx, tableId, &ChangeEvent{Type: ChangeUpdate, TableName: tableName, RowId: rowId, Before: tpl, After: after})
+	}
+	return bormDb.fireTriggers(tx, tableId, tpl, newRow)
 }
 
-//Truncate table, not support tx
+//Truncate table, not support tx, ErrForeignKeyViolation while another table referencing it
+//has rows, truncate the referencing tables first
 func (bormDb *BormDb) Truncate(row IRow) error {
-	tableName := row.GetTableName()
+	tableName := bormDb.tableManager.GetTableName(row)
 	id, err := bormDb.tableManager.GetTableId(tableName)
 	if err != nil {
 		return err
 	}
+	err = bormDb.View(func(txn *badger.Txn) error {
+		for _, fk := range bormDb.tableManager.GetReferences(id) {
+			if fk.childTableId != id && bormDb.countWithPrefixUpTo(txn, encodeTablePrefixKey(fk.childTableId), 1) > 0 {
+				return errors.Wrapf(ErrForeignKeyViolation, "referenced by foreign key %s", fk.name)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	prefixes := bormDb.tableKeyPrefixes(id)
+	for i := 0; i < len(prefixes); i++ {
+		bormDb.db.DropPrefix(prefixes[i])
+	}
+	return nil
+}
+
+//indexKeyPrefix
+//the prefix of the entries of a unique, normal or fulltext index
+func indexKeyPrefix(tableId, fieldIdx uint32, tag *tag) []byte {
+	if tag.CheckIsUnique() {
+		return encodeUqIndexKeyPrefix(tableId, fieldIdx)
+	} else if tag.CheckIsFulltext() {
+		return encodeFulltextIndexPrefix(tableId, fieldIdx)
+	}
+	return encodeNormalIndexPrefix(tableId, fieldIdx)
+}
+
+func (bormDb *BormDb) tableKeyPrefixes(id uint32) [][]byte {
 	prefixes := [][]byte{}
-	prefixes = append(prefixes, encodeTablePrefixKey(id))
+	prefixes = append(prefixes, encodeTablePrefixKey(id), encodeReferencePrefix(id))
 	indexTags := bormDb.tableManager.GetIndexTags(id)
 	for fieldIdx, tag := range indexTags {
-		if tag.CheckIsUnique() {
-			prefixes = append(prefixes, encodeUqIndexKeyPrefix(id, fieldIdx))
-		} else if tag.CheckIsNormal() {
-			prefixes = append(prefixes, encodeNormalIndexPrefix(id, fieldIdx))
-		}
+		prefixes = append(prefixes, indexKeyPrefix(id, fieldIdx, tag))
 	}
-	if len(bormDb.tableManager.GetUnionTags(id)) > 0 {
+	if len(bormDb.tableManager.GetUnionIndexes(id)) > 0 {
 		prefixes = append(prefixes, encodeUnionIndexPrefix(id))
 	}
+	return prefixes
+}
 
-	for i := 0; i < len(prefixes); i++ {
-		bormDb.db.DropPrefix(prefixes[i])
+//DropTable
+//remove all rows, index entries and the catalog entry of the table, not support tx
+func (bormDb *BormDb) DropTable(row IRow) error {
+	tableName := bormDb.tableManager.GetTableName(row)
+	id, err := bormDb.tableManager.GetTableId(tableName)
+	if err != nil {
+		return err
 	}
-	return nil
+	prefixes := bormDb.tableKeyPrefixes(id)
+	err = bormDb.tableManager.DropTable(tableName)
+	if err != nil {
+		return err
+	}
+	bormDb.triggers.remove(id)
+	bormDb.changes.removeTable(id)
+	err = bormDb.db.DropPrefix(prefixes...)
+	if err != nil {
+		return err
+	}
+	return bormDb.db.Update(func(txn *badger.Txn) error {
+		err := txn.Delete(encodeSeqKey(id))
+		if err != nil {
+			return err
+		}
+		return txn.Delete(encodeSchemaKey(id))
+	})
+}
+
+//RenameTable
+//rows keep their table id and are still accessed through their registered row type
+func (bormDb *BormDb) RenameTable(tableName, newTableName string) error {
+	return bormDb.tableManager.RenameTable(tableName, newTableName)
+}
+
+type IndexInfo struct {
+	Name   string
+	Type   IndexType
+	Fields []string
+	//a unique index or a union index without the multi modifier
+	Unique   bool
+	Building bool
+	Partial  bool
+}
+
+type TableInfo struct {
+	Name     string
+	Id       uint32
+	Indexes  []IndexInfo
+	RowCount uint64
+}
+
+//Tables
+//list all registered tables ordered by id
+func (bormDb *BormDb) Tables() ([]TableInfo, error) {
+	ids, names := bormDb.tableManager.GetTables()
+	tables := make([]TableInfo, 0, len(ids))
+	err := bormDb.View(func(txn *badger.Txn) error {
+		for _, id := range ids {
+			tables = append(tables, TableInfo{
+				Name:     names[id],
+				Id:       id,
+				Indexes:  bormDb.tableManager.GetIndexInfos(id),
+				RowCount: bormDb.countWithPrefix(txn, encodeTablePrefixKey(id)),
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return tables, nil
 }
 
 //Close
 func (bormDb *BormDb) Close() error {
-	return bormDb.db.Close()
+	bormDb.snapshots.close()
+	bormDb.changes.close()
+	err := bormDb.tableManager.Close()
+	if closeErr := bormDb.db.Close(); closeErr != nil {
+		return closeErr
+	}
+	return err
 }
 
 func (bormDb *BormDb) View(fn func(txn *badger.Txn) error) error {
@@ -257,7 +606,11 @@ func (bormDb *BormDb) View(fn func(txn *badger.Txn) error) error {
 }
 
 func (bormDb *BormDb) TxQueryWithNormalIndex(txn *badger.Txn, row IRow, idx uint32, val any) ([]uint64, error) {
-	tableId, err := bormDb.tableManager.GetTableId(row.GetTableName())
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return nil, err
+	}
+	val, err = bormDb.normalizeQueryValue(tableId, idx, val)
 	if err != nil {
 		return nil, err
 	}
@@ -265,6 +618,8 @@ func (bormDb *BormDb) TxQueryWithNormalIndex(txn *badger.Txn, row IRow, idx uint
 	defer it.Close()
 	prefix := encodeNormalIndexKeyPrefix(tableId, idx, val)
 	ids := []uint64{}
+	//values containing ':' may share the prefix, a row of a multi-valued index is only returned once
+	seen := map[uint64]bool{}
 	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
 		itemKey := it.Item().Key()
 		lastIndex := bytes.LastIndexByte(itemKey, ':')
@@ -272,13 +627,79 @@ func (bormDb *BormDb) TxQueryWithNormalIndex(txn *badger.Txn, row IRow, idx uint
 		if err != nil {
 			return nil, err
 		}
-		ids = append(ids, id)
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+//TxQueryWithIndexPrefix
+//ids of the rows whose string value of the unique or normal index idx starts with prefix
+func (bormDb *BormDb) TxQueryWithIndexPrefix(txn *badger.Txn, row IRow, idx uint32, prefix string) ([]uint64, error) {
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return nil, err
+	}
+	tag, ok := bormDb.tableManager.GetIndexTags(tableId)[idx]
+	if !ok || tag.fieldType != String {
+		return nil, ErrIdxNotSupport
+	}
+	if tag.caseInsensitive {
+		prefix = foldPrefix(prefix)
+	}
+	var fieldPrefix []byte
+	if tag.CheckIsUnique() {
+		fieldPrefix = encodeUqIndexKeyPrefix(tableId, idx)
+	} else {
+		fieldPrefix = encodeNormalIndexPrefix(tableId, idx)
+	}
+	seek := append(append([]byte{}, fieldPrefix...), prefix...)
+	opt := badger.DefaultIteratorOptions
+	opt.PrefetchValues = tag.CheckIsUnique()
+	it := txn.NewIterator(opt)
+	defer it.Close()
+	ids := []uint64{}
+	seen := map[uint64]bool{}
+	for it.Seek(seek); it.ValidForPrefix(seek); it.Next() {
+		item := it.Item()
+		id := uint64(0)
+		if tag.CheckIsUnique() {
+			err = item.Value(func(val []byte) error {
+				id = common.DecodedToUInt64(val)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			//the value is between the field prefix and the last ':', the row id of
+			//a shorter value may start with the rest of the prefix
+			itemKey := item.Key()
+			lastIndex := bytes.LastIndexByte(itemKey, ':')
+			if lastIndex < len(seek) {
+				continue
+			}
+			id, err = strconv.ParseUint(string(itemKey[lastIndex+1:]), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
 	}
 	return ids, nil
 }
 
 func (bormDb *BormDb) TxQueryWithUniqueIndex(txn *badger.Txn, row IRow, idx uint32, val any) (uint64, error) {
-	tableId, err := bormDb.tableManager.GetTableId(row.GetTableName())
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return 0, err
+	}
+	val, err = bormDb.normalizeQueryValue(tableId, idx, val)
 	if err != nil {
 		return 0, err
 	}
@@ -297,80 +718,117 @@ func (bormDb *BormDb) TxQueryWithUniqueIndex(txn *badger.Txn, row IRow, idx uint
 	return id, nil
 }
 
-func (bormDb *BormDb) TxQueryWithUnionIndex(txn *badger.Txn, row IRow, idxConditionsMap map[uint32]any) (uint64, error) {
-	tableId, err := bormDb.tableManager.GetTableId(row.GetTableName())
-	if err != nil {
-		return 0, err
+//normalizeQueryValue
+//convert a query value to the canonical value of the indexed field
+func (bormDb *BormDb) normalizeQueryValue(tableId, idx uint32, val any) (any, error) {
+	tag, ok := bormDb.tableManager.GetIndexTags(tableId)[idx]
+	if !ok {
+		return val, nil
 	}
+	return tag.normalize(val)
+}
 
-	keys := make([]int, 0)
-
-	for k := range idxConditionsMap {
-		keys = append(keys, int(k))
-	}
-	sort.Ints(keys)
-	indexContent := ""
-	for i, key := range keys {
-		if i == len(keys)-1 {
-			indexContent += fmt.Sprintf("%v:%v", key, idxConditionsMap[uint32(key)])
-		} else {
-			indexContent += fmt.Sprintf("%v:%v:", key, idxConditionsMap[uint32(key)])
-		}
-	}
-	item, err := txn.Get(encodeUnionIndexKey(tableId, indexContent))
-	if err != nil {
-		return 0, err
-	}
-	id := uint64(0)
-	err = item.Value(func(val []byte) error {
-		id = common.DecodedToUInt64(val)
-		return nil
-	})
+//TxQueryWithUnionIndex
+//query the unique union index covering exactly the fields of idxConditionsMap
+func (bormDb *BormDb) TxQueryWithUnionIndex(txn *badger.Txn, row IRow, idxConditionsMap map[uint32]any) (uint64, error) {
+	ids, err := bormDb.txQueryWithUnionConditions(txn, row, idxConditionsMap, true)
 	if err != nil {
 		return 0, err
 	}
-	return id, nil
+	return ids[0], nil
 }
 
 func (bormDb *BormDb) TxQueryWithUnionIndexWithFieldMap(txn *badger.Txn, row IRow, conditionsMap map[string]any) (uint64, error) {
-	tableId, err := bormDb.tableManager.GetTableId(row.GetTableName())
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
 	if err != nil {
 		return 0, err
 	}
-
 	idxConditionsMap := bormDb.tableManager.GetUnionTagsByFieldConditions(tableId, conditionsMap)
+	return bormDb.TxQueryWithUnionIndex(txn, row, idxConditionsMap)
+}
+
+//TxQueryWithMultiUnionIndex
+//query the non-unique union index covering exactly the fields of idxConditionsMap,
+//ids are ordered by row id within the key
+func (bormDb *BormDb) TxQueryWithMultiUnionIndex(txn *badger.Txn, row IRow, idxConditionsMap map[uint32]any) ([]uint64, error) {
+	return bormDb.txQueryWithUnionConditions(txn, row, idxConditionsMap, false)
+}
 
-	keys := make([]int, 0)
+func (bormDb *BormDb) TxQueryWithMultiUnionIndexWithFieldMap(txn *badger.Txn, row IRow, conditionsMap map[string]any) ([]uint64, error) {
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return nil, err
+	}
+	idxConditionsMap := bormDb.tableManager.GetUnionTagsByFieldConditions(tableId, conditionsMap)
+	return bormDb.TxQueryWithMultiUnionIndex(txn, row, idxConditionsMap)
+}
 
+func (bormDb *BormDb) txQueryWithUnionConditions(txn *badger.Txn, row IRow, idxConditionsMap map[uint32]any, unique bool) ([]uint64, error) {
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return nil, err
+	}
+	fieldIdxes := make([]uint32, 0, len(idxConditionsMap))
 	for k := range idxConditionsMap {
-		keys = append(keys, int(k))
+		fieldIdxes = append(fieldIdxes, k)
 	}
-	sort.Ints(keys)
-	indexContent := ""
-	for i, key := range keys {
-		if i == len(keys)-1 {
-			indexContent += fmt.Sprintf("%v:%v", key, idxConditionsMap[uint32(key)])
-		} else {
-			indexContent += fmt.Sprintf("%v:%v:", key, idxConditionsMap[uint32(key)])
-		}
+	union, err := bormDb.tableManager.GetUnionIndex(tableId, fieldIdxes, unique)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]any, len(union.fields))
+	for i, fieldIdx := range union.fields {
+		vals[i] = idxConditionsMap[fieldIdx]
 	}
-	item, err := txn.Get(encodeUnionIndexKey(tableId, indexContent))
+	return bormDb.txQueryWithUnion(txn, tableId, union, vals)
+}
+
+//txQueryWithUnion
+//ids of the rows whose leading union fields equal vals, a unique union given all of its
+//fields is a point lookup returning ErrKeyNotFound without a match, any shorter
+//left prefix of the fields is served by a prefix scan
+func (bormDb *BormDb) txQueryWithUnion(txn *badger.Txn, tableId uint32, union *unionIndex, vals []any) ([]uint64, error) {
+	tags, err := unionTags(union, bormDb.tableManager.GetIndexTags(tableId))
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	id := uint64(0)
-	err = item.Value(func(val []byte) error {
-		id = common.DecodedToUInt64(val)
-		return nil
-	})
+	indexContent, err := encodeUnionIndexContent(tags, vals)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
-	return id, nil
+	key := encodeUnionIndexKey(tableId, union.name, indexContent)
+	if union.unique && len(vals) == len(union.fields) {
+		item, err := txn.Get(key)
+		if err != nil {
+			return nil, err
+		}
+		id := uint64(0)
+		err = item.Value(func(val []byte) error {
+			id = common.DecodedToUInt64(val)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		return []uint64{id}, nil
+	}
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	ids := []uint64{}
+	for it.Seek(key); it.ValidForPrefix(key); it.Next() {
+		err := it.Item().Value(func(val []byte) error {
+			ids = append(ids, common.DecodedToUInt64(val))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ids, nil
 }
 
 func (bormDb *BormDb) TxQueryWithPk(txn *badger.Txn, row IRow, ids []uint64, f func(IRow) error) error {
-	tableId, err := bormDb.tableManager.GetTableId(row.GetTableName())
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
 	if err != nil {
 		return err
 	}
@@ -381,8 +839,7 @@ func (bormDb *BormDb) TxQueryWithPk(txn *badger.Txn, row IRow, ids []uint64, f f
 			return err
 		}
 		err = item.Value(func(val []byte) error {
-			tp := row.Clone().(IRow)
-			err = tp.Unmarshal(val)
+			tp, err := bormDb.tableManager.Unmarshal(tableId, row, val)
 			if err != nil {
 				return err
 			}
@@ -396,7 +853,7 @@ func (bormDb *BormDb) TxQueryWithPk(txn *badger.Txn, row IRow, ids []uint64, f f
 }
 
 func (bormDb *BormDb) TxForeach(txn *badger.Txn, row IRow, f func(IRow) error) error {
-	id, err := bormDb.tableManager.GetTableId(row.GetTableName())
+	id, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
 	if err != nil {
 		return err
 	}
@@ -405,8 +862,7 @@ func (bormDb *BormDb) TxForeach(txn *badger.Txn, row IRow, f func(IRow) error) e
 	prefix := encodeTablePrefixKey(id)
 	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
 		err := it.Item().Value(func(v []byte) error {
-			tp := row.Clone().(IRow)
-			err = tp.Unmarshal(v)
+			tp, err := bormDb.tableManager.Unmarshal(id, row, v)
 			if err != nil {
 				return err
 			}
@@ -443,7 +899,7 @@ func (bormDb *BormDb) Count(row IRow) (count uint64, err error) {
 }
 
 func (bormDb *BormDb) TxCount(txn *badger.Txn, row IRow) (uint64, error) {
-	id, err := bormDb.tableManager.GetTableId(row.GetTableName())
+	id, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
 	if err != nil {
 		return 0, err
 	}
@@ -453,31 +909,93 @@ func (bormDb *BormDb) TxCount(txn *badger.Txn, row IRow) (uint64, error) {
 }
 
 func (bormDb *BormDb) GetFieldValWithFieldName(item IRow, FieldName string) (any, error) {
-	tableName := item.GetTableName()
+	tableName := bormDb.tableManager.GetTableName(item)
 	tableId, err := bormDb.tableManager.GetTableId(tableName)
 	if err != nil {
 		return nil, err
 	}
+	err = bormDb.tableManager.CheckRow(tableId, item)
+	if err != nil {
+		return nil, err
+	}
 	tag, err := bormDb.tableManager.GetIndexTag(tableId, FieldName)
 	if err != nil {
 		return nil, err
 	}
-	ptr0 := common.GetUnsafeInterfaceUintptr(item)
-	val := tag.GetPointerVal(unsafe.Pointer(uintptr(ptr0) + tag.offset))
-	return val, nil
+	return firstRowValue(tag, item)
 }
 
 func (bormDb *BormDb) GetFieldValWithFieldIndex(item IRow, fieldIdx uint32) (any, error) {
-	tableName := item.GetTableName()
+	tableName := bormDb.tableManager.GetTableName(item)
 	tableId, err := bormDb.tableManager.GetTableId(tableName)
 	if err != nil {
 		return nil, err
 	}
+	err = bormDb.tableManager.CheckRow(tableId, item)
+	if err != nil {
+		return nil, err
+	}
 	tagMap := bormDb.tableManager.GetIndexTags(tableId)
-	tag := tagMap[fieldIdx]
-	ptr0 := common.GetUnsafeInterfaceUintptr(item)
-	val := tag.GetPointerVal(unsafe.Pointer(uintptr(ptr0) + tag.offset))
-	return val, nil
+	tag, ok := tagMap[fieldIdx]
+	if !ok {
+		return nil, ErrFieldNotFound
+	}
+	return firstRowValue(tag, item)
+}
+
+func (bormDb *BormDb) getFieldValsWithFieldIndex(item IRow, fieldIdx uint32) ([]any, error) {
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(item))
+	if err != nil {
+		return nil, err
+	}
+	err = bormDb.tableManager.CheckRow(tableId, item)
+	if err != nil {
+		return nil, err
+	}
+	tag, ok := bormDb.tableManager.GetIndexTags(tableId)[fieldIdx]
+	if !ok {
+		return nil, ErrFieldNotFound
+	}
+	return tag.indexValues(item), nil
+}
+
+//GetRowId
+//the Id of a row of a registered table
+func (bormDb *BormDb) GetRowId(row IRow) (uint64, error) {
+	accessor, err := bormDb.tableManager.lookupRowAccessor(row)
+	if err != nil {
+		return 0, err
+	}
+	return accessor.getId(row)
+}
+
+//SetRowId
+//set the Id of a row of a registered table
+func (bormDb *BormDb) SetRowId(row IRow, id uint64) error {
+	accessor, err := bormDb.tableManager.lookupRowAccessor(row)
+	if err != nil {
+		return err
+	}
+	return accessor.setId(row, id)
+}
+
+//firstRowValue
+//the value of a field, or the first value of a multi-valued path index
+func firstRowValue(tag *tag, item IRow) (any, error) {
+	return firstValue(tag.rowValues(item))
+}
+
+//firstIndexValue
+//like firstRowValue in the form of the index keys
+func firstIndexValue(tag *tag, item IRow) (any, error) {
+	return firstValue(tag.indexValues(item))
+}
+
+func firstValue(vals []any) (any, error) {
+	if len(vals) == 0 {
+		return nil, ErrFieldNotFound
+	}
+	return vals[0], nil
 }
 
 //Dump
@@ -494,14 +1012,16 @@ func (bormDb *BormDb) Dump(tp IRow) ([]IRow, error) {
 //Snoop
 //output all table row data count, index count
 func (bormDb *BormDb) Snoop(tp IRow) (*TableDetails, error) {
-	tableName := tp.GetTableName()
+	tableName := bormDb.tableManager.GetTableName(tp)
 	id, err := bormDb.tableManager.GetTableId(tableName)
 	if err != nil {
 		return nil, err
 	}
 	tableDetails := &TableDetails{
-		UniqueIndex: map[string]uint64{},
-		NormalIndex: map[string]uint64{},
+		UniqueIndex:   map[string]uint64{},
+		NormalIndex:   map[string]uint64{},
+		UnionIndex:    map[string]uint64{},
+		FulltextIndex: map[string]uint64{},
 	}
 	err = bormDb.View(func(txn *badger.Txn) error {
 
@@ -515,18 +1035,24 @@ func (bormDb *BormDb) Snoop(tp IRow) (*TableDetails, error) {
 		for fieldIdx, tag := range indexTags {
 			if tag.CheckIsUnique() {
 				count := bormDb.countWithPrefix(txn, encodeUqIndexKeyPrefix(id, fieldIdx))
-				tableDetails.UniqueIndex[tag.fieldName] = count
+				tableDetails.UniqueIndex[tag.name()] = count
 				continue
 			}
 			if tag.CheckIsNormal() {
 				count := bormDb.countWithPrefix(txn, encodeNormalIndexPrefix(id, fieldIdx))
-				tableDetails.NormalIndex[tag.fieldName] = count
+				tableDetails.NormalIndex[tag.name()] = count
+				continue
+			}
+			if tag.CheckIsFulltext() {
+				count := bormDb.countWithPrefix(txn, encodeFulltextIndexPrefix(id, fieldIdx))
+				tableDetails.FulltextIndex[tag.name()] = count
 				continue
 			}
 		}
-		if len(bormDb.tableManager.GetUnionTags(id)) > 0 {
-			count := bormDb.countWithPrefix(txn, encodeUnionIndexPrefix(id))
-			tableDetails.UnionIndexCount = count
+		for _, union := range bormDb.tableManager.GetUnionIndexes(id) {
+			count := bormDb.countWithPrefix(txn, encodeUnionIndexNamePrefix(id, union.name))
+			tableDetails.UnionIndex[union.name] = count
+			tableDetails.UnionIndexCount += count
 		}
 		return nil
 	})
@@ -546,56 +1072,118 @@ func (bormDb *BormDb) countWithPrefix(txn *badger.Txn, prefix []byte) uint64 {
 	return count
 }
 
-func (bormDb *BormDb) createIndex(tableId uint32, item IRow, txn *badger.Txn, next uint64) error {
+//expiresAtOf
+//badger expires entries at whole unix seconds, round up so a row never expires early
+func expiresAtOf(at time.Time) uint64 {
+	expiresAt := uint64(at.Unix())
+	if at.Nanosecond() > 0 {
+		expiresAt++
+	}
+	return expiresAt
+}
+
+func setEntry(txn *badger.Txn, key, val []byte, expiresAt uint64) error {
+	if expiresAt == 0 {
+		return txn.Set(key, val)
+	}
+	entry := badger.NewEntry(key, val)
+	entry.ExpiresAt = expiresAt
+	return txn.SetEntry(entry)
+}
+
+func (bormDb *BormDb) createIndex(tableId uint32, item IRow, txn *badger.Txn, next uint64, expiresAt uint64) error {
 	indexTags := bormDb.tableManager.GetIndexTags(tableId)
 	//not found index setup in table
 	if len(indexTags) == 0 {
 		return nil
 	}
-	ptr0 := common.GetUnsafeInterfaceUintptr(item)
+	rowValue := rowStruct(item)
 	for fieldIdx, tag := range indexTags {
-		val := tag.GetPointerVal(unsafe.Pointer(uintptr(ptr0) + tag.offset))
-		if tag.CheckIsUnique() {
-			key := encodeUqIndexKey(tableId, fieldIdx, val)
-			if _, err := txn.Get(key); err == nil {
-				return ErrIdxUniqueConflict
-			}
-			err := txn.Set(key, common.EncodedFromUInt64(next))
+		if !tag.matchRow(item) {
+			continue
+		}
+		for _, val := range tag.indexValues(item) {
+			err := bormDb.createFieldIndex(tableId, fieldIdx, tag, val, txn, next, expiresAt)
 			if err != nil {
 				return err
 			}
-		} else if tag.CheckIsNormal() {
-			key := encodeNormalIndexKey(tableId, fieldIdx, val, next)
-			err := txn.Set(key, nil)
+		}
+	}
+	for _, union := range bormDb.tableManager.GetUnionIndexes(tableId) {
+		indexContent, err := bormDb.unionIndexContent(union, indexTags, rowValue)
+		if err != nil {
+			return err
+		}
+		if !union.unique {
+			err = setEntry(txn, encodeMultiUnionIndexKey(tableId, union.name, indexContent, next), common.EncodedFromUInt64(next), expiresAt)
 			if err != nil {
 				return err
 			}
+			continue
+		}
+		key := encodeUnionIndexKey(tableId, union.name, indexContent)
+		if _, err := txn.Get(key); err == nil {
+			return ErrIdxUniqueConflict
+		}
+		err = setEntry(txn, key, common.EncodedFromUInt64(next), expiresAt)
+		if err != nil {
+			return err
 		}
 	}
-	//not found union index setup in table
-	unionTags := bormDb.tableManager.GetUnionTags(tableId)
-	if len(unionTags) == 0 {
-		return nil
+	return nil
+}
+
+func (bormDb *BormDb) unionIndexContent(union *unionIndex, indexTags map[uint32]*tag, rowValue reflect.Value) ([]byte, error) {
+	tags, err := unionTags(union, indexTags)
+	if err != nil {
+		return nil, err
+	}
+	vals := make([]any, len(tags))
+	for i, tag := range tags {
+		vals[i] = tag.fieldValue(rowValue.Field(tag.index))
 	}
-	indexContent := ""
-	for i, fieldIdx := range unionTags {
+	return encodeUnionIndexContent(tags, vals)
+}
+
+func unionTags(union *unionIndex, indexTags map[uint32]*tag) ([]*tag, error) {
+	tags := make([]*tag, len(union.fields))
+	for i, fieldIdx := range union.fields {
 		tag, ok := indexTags[fieldIdx]
 		if !ok {
-			bormDb.db.Opts().Logger.Warningf("Union tag not found in indexTags,%v,%v\n", unionTags, indexTags)
-			return ErrIdxNotSupport
-		}
-		val := tag.GetPointerVal(unsafe.Pointer(uintptr(ptr0) + tag.offset))
-		if i == len(unionTags)-1 {
-			indexContent += fmt.Sprintf("%v:%v", fieldIdx, val)
-		} else {
-			indexContent += fmt.Sprintf("%v:%v:", fieldIdx, val)
+			return nil, ErrIdxNotSupport
 		}
+		tags[i] = tag
 	}
-	key := encodeUnionIndexKey(tableId, indexContent)
-	if _, err := txn.Get(key); err == nil {
-		return ErrIdxUniqueConflict
+	return tags, nil
+}
+
+//createFieldIndex
+//write the unique, normal or fulltext index entries of a single field,
+//rewriting the unique entry that already points to the same row is allowed
+func (bormDb *BormDb) createFieldIndex(tableId, fieldIdx uint32, tag *tag, val any, txn *badger.Txn, next uint64, expiresAt uint64) error {
+	if tag.CheckIsUnique() {
+		key := encodeUqIndexKey(tableId, fieldIdx, val)
+		if item, err := txn.Get(key); err == nil {
+			id := uint64(0)
+			err = item.Value(func(val []byte) error {
+				id = common.DecodedToUInt64(val)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if id != next {
+				return ErrIdxUniqueConflict
+			}
+		}
+		return setEntry(txn, key, common.EncodedFromUInt64(next), expiresAt)
+	} else if tag.CheckIsNormal() {
+		key := encodeNormalIndexKey(tableId, fieldIdx, val, next)
+		return setEntry(txn, key, nil, expiresAt)
+	} else if tag.CheckIsFulltext() {
+		return bormDb.createFulltextIndex(tableId, fieldIdx, val, txn, next, expiresAt)
 	}
-	return txn.Set(key, common.EncodedFromUInt64(next))
+	return nil
 }
 
 func (bormDb *BormDb) deleteIndex(tableId uint32, item IRow, txn *badger.Txn) error {
@@ -603,39 +1191,41 @@ func (bormDb *BormDb) deleteIndex(tableId uint32, item IRow, txn *badger.Txn) er
 	if len(indexTags) == 0 {
 		return nil
 	}
-	ptr0 := common.GetUnsafeInterfaceUintptr(item)
+	rowValue := rowStruct(item)
 	for i, tag := range indexTags {
-		val := tag.GetPointerVal(unsafe.Pointer(uintptr(ptr0) + tag.offset))
-		if tag.CheckIsUnique() {
-			key := encodeUqIndexKey(tableId, i, val)
-			if err := txn.Delete(key); err != nil {
-				return err
-			}
-		} else if tag.CheckIsNormal() {
-			key := encodeNormalIndexKey(tableId, i, val, common.GetUint64(item))
-			if err := txn.Delete(key); err != nil {
-				return err
+		if !tag.matchRow(item) {
+			continue
+		}
+		for _, val := range tag.indexValues(item) {
+			if tag.CheckIsUnique() {
+				key := encodeUqIndexKey(tableId, i, val)
+				if err := txn.Delete(key); err != nil {
+					return err
+				}
+			} else if tag.CheckIsNormal() {
+				key := encodeNormalIndexKey(tableId, i, val, rowId(item))
+				if err := txn.Delete(key); err != nil {
+					return err
+				}
+			} else if tag.CheckIsFulltext() {
+				if err := bormDb.deleteFulltextIndex(tableId, i, val, txn, rowId(item)); err != nil {
+					return err
+				}
 			}
 		}
 	}
-	unionTags := bormDb.tableManager.GetUnionTags(tableId)
-	if len(unionTags) == 0 {
-		return nil
-	}
-	indexContent := ""
-	for i, fieldIdx := range unionTags {
-		tag, ok := indexTags[fieldIdx]
-		if !ok {
-			bormDb.db.Opts().Logger.Warningf("Union tag not found in indexTags,%v,%v\n", unionTags, indexTags)
-			return ErrIdxNotSupport
+	for _, union := range bormDb.tableManager.GetUnionIndexes(tableId) {
+		indexContent, err := bormDb.unionIndexContent(union, indexTags, rowValue)
+		if err != nil {
+			return err
 		}
-		val := tag.GetPointerVal(unsafe.Pointer(uintptr(ptr0) + tag.offset))
-		if i == len(unionTags)-1 {
-			indexContent += fmt.Sprintf("%v:%v", fieldIdx, val)
-		} else {
-			indexContent += fmt.Sprintf("%v:%v:", fieldIdx, val)
+		key := encodeUnionIndexKey(tableId, union.name, indexContent)
+		if !union.unique {
+			key = encodeMultiUnionIndexKey(tableId, union.name, indexContent, rowId(item))
+		}
+		if err := txn.Delete(key); err != nil {
+			return err
 		}
 	}
-	key := encodeUnionIndexKey(tableId, indexContent)
-	return txn.Delete(key)
+	return nil
 }
@@ -1,9 +1,11 @@
 package borm
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/longbridgeapp/borm/pb"
 
@@ -40,6 +42,111 @@ func TestCreateTable(t *testing.T) {
 	})
 }
 
+//illegalRow
+//a row whose Clone, GetTableName or codec misbehaves depending on mode
+type illegalRow struct {
+	Id    uint64
+	Name  string `idx:"normal"`
+	mode  string
+	calls int
+}
+
+func (row *illegalRow) Marshal() ([]byte, error) {
+	if row.mode == "codec" {
+		return []byte{1}, nil
+	}
+	return nil, nil
+}
+
+func (row *illegalRow) Unmarshal(data []byte) error {
+	if len(data) > 0 {
+		return errors.New("unexpected data")
+	}
+	return nil
+}
+
+func (row *illegalRow) GetTableName() string {
+	row.calls++
+	if row.mode == "name" {
+		return fmt.Sprintf("IllegalRow_%d", row.calls)
+	}
+	return "IllegalRow"
+}
+
+func (row *illegalRow) Clone() any {
+	if row.mode == "clone" {
+		return &pb.Person{}
+	}
+	return &illegalRow{mode: row.mode}
+}
+
+func TestCreateTableRowCheck(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&illegalRow{mode: "clone"})
+		require.ErrorIs(t, err, ErrRowCloneIllegal)
+		require.Contains(t, err.Error(), "*pb.Person")
+		err = db.CreateTable(&illegalRow{mode: "name"})
+		require.ErrorIs(t, err, ErrTableNameUnstable)
+		err = db.CreateTable(&illegalRow{mode: "codec"})
+		require.ErrorIs(t, err, ErrRowCodecIllegal)
+		tables, err := db.Tables()
+		require.NoError(t, err)
+		require.Empty(t, tables)
+		err = db.CreateTable(&illegalRow{})
+		require.NoError(t, err)
+		err = db.Insert(&illegalRow{Name: "jacky"})
+		require.NoError(t, err)
+	})
+}
+
+//shadowPerson
+//inherits the table name of pb.Person without being a pb.Person
+type shadowPerson struct {
+	pb.Person
+}
+
+func TestRowTypeMismatch(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.Insert(&shadowPerson{})
+		require.ErrorIs(t, err, ErrRowTypeMismatch)
+		err = db.Insert((*pb.Person)(nil))
+		require.ErrorIs(t, err, ErrRowTypeMismatch)
+		person := &pb.Person{Name: "jacky", Phone: "+861", Age: 30}
+		err = db.Insert(person)
+		require.NoError(t, err)
+		id, err := db.GetRowId(person)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), id)
+		err = db.Update(id, &shadowPerson{})
+		require.ErrorIs(t, err, ErrRowTypeMismatch)
+		err = db.Delete(id, &shadowPerson{})
+		require.ErrorIs(t, err, ErrRowTypeMismatch)
+		_, err = db.GetFieldValWithFieldName(&shadowPerson{}, "Name")
+		require.ErrorIs(t, err, ErrRowTypeMismatch)
+		err = db.SetRowId(&shadowPerson{}, 2)
+		require.ErrorIs(t, err, ErrRowTypeMismatch)
+		name, err := db.GetFieldValWithFieldName(person, "Name")
+		require.NoError(t, err)
+		require.Equal(t, "jacky", name)
+		count, err := db.Count(&pb.Person{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), count)
+
+		//the accessor cached for the row type goes with the dropped table
+		err = db.DropTable(&pb.Person{})
+		require.NoError(t, err)
+		_, err = db.GetRowId(person)
+		require.ErrorIs(t, err, ErrTableNotFound)
+		err = db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.SetRowId(person, 7)
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), person.Id)
+	})
+}
+
 func TestManageTable(t *testing.T) {
 	t.Run("Snoop", func(t *testing.T) {
 		runNewBorm(t, func(t *testing.T, db *BormDb) {
@@ -1040,3 +1147,287 @@ func TestDbCount(t *testing.T) {
 
 // 	_ = a
 // }
+
+func TestTrigger(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Order{})
+		require.NoError(t, err)
+		err = db.CreateTable(&pb.AccountInfo{})
+		require.NoError(t, err)
+		err = db.Insert(&pb.AccountInfo{
+			Aaid:           10000,
+			AccountChannel: "lb",
+			CashBooks:      make(map[string]*pb.Detail),
+		})
+		require.NoError(t, err)
+
+		err = db.OnChange(&pb.Order{}, func(tx *badger.Txn, before, after IRow) error {
+			if after == nil {
+				return nil
+			}
+			order := after.(*pb.Order)
+			if order.EntrustStatus != 1 {
+				return nil
+			}
+			accountInfo, err := TxFirst(tx, db, WithAnd(&pb.AccountInfo{}).Eq("AccountChannel", order.AccountChannel).Eq("Aaid", order.Aaid))
+			if err != nil {
+				return err
+			}
+			accountInfo.CashBooks = map[string]*pb.Detail{
+				order.Currency: {OutStanding: "-" + order.EntrustAmount},
+			}
+			return db.TxUpdate(tx, accountInfo.Id, accountInfo)
+		})
+		require.NoError(t, err)
+
+		order := &pb.Order{AccountChannel: "lb", Aaid: 10000, OrderId: "1", Currency: "HKD", EntrustAmount: "1000"}
+		err = db.Insert(order)
+		require.NoError(t, err)
+		accountInfo, err := First(db, WithAnd(&pb.AccountInfo{}).Eq("AccountChannel", "lb").Eq("Aaid", uint64(10000)))
+		require.NoError(t, err)
+		require.Equal(t, 0, len(accountInfo.CashBooks))
+
+		order.EntrustStatus = 1
+		err = db.Update(order.Id, order)
+		require.NoError(t, err)
+		accountInfo, err = First(db, WithAnd(&pb.AccountInfo{}).Eq("AccountChannel", "lb").Eq("Aaid", uint64(10000)))
+		require.NoError(t, err)
+		require.Equal(t, "-1000", accountInfo.CashBooks["HKD"].OutStanding)
+
+		//trigger failure aborts the source write
+		order = &pb.Order{AccountChannel: "lb", Aaid: 10001, OrderId: "2", Currency: "HKD", EntrustStatus: 1}
+		err = db.Insert(order)
+		require.ErrorIs(t, err, ErrKeyNotFound)
+		count, err := db.Count(&pb.Order{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), count)
+	})
+}
+
+func TestTTL(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		//expiry is rounded up to whole seconds, a 2s ttl expires within 2s to 3s
+		err := db.CreateTable(&pb.Person{}, WithTableTTL(2*time.Second))
+		require.NoError(t, err)
+		err = db.CreateTable(&pb.Order{})
+		require.NoError(t, err)
+
+		err = db.Insert(&pb.Person{Name: "jacky", Phone: "+861", Age: 30})
+		require.NoError(t, err)
+		err = db.InsertWithTTL(&pb.Person{Name: "jacky", Phone: "+862", Age: 30}, time.Hour)
+		require.NoError(t, err)
+		err = db.Insert(&pb.Order{AccountChannel: "lb", Aaid: 1, OrderId: "1", CounterId: "ST/HK/700"})
+		require.NoError(t, err)
+		err = db.InsertWithTTL(&pb.Order{AccountChannel: "lb", Aaid: 1, OrderId: "2", CounterId: "ST/HK/700"}, 2*time.Second)
+		require.NoError(t, err)
+		err = db.Update(1, &pb.Person{Name: "jacky", Phone: "+861", Age: 31})
+		require.NoError(t, err)
+
+		persons, err := Find(db, WithAnd(&pb.Person{}).Eq("Name", "jacky"))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(persons))
+
+		time.Sleep(4 * time.Second)
+
+		persons, err = Find(db, WithAnd(&pb.Person{}).Eq("Name", "jacky"))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(persons))
+		require.Equal(t, "+862", persons[0].Phone)
+		_, err = First(db, WithAnd(&pb.Person{}).Eq("Phone", "+861"))
+		require.ErrorIs(t, err, ErrKeyNotFound)
+
+		detail, err := db.Snoop(&pb.Person{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), detail.TotalCount)
+		require.Equal(t, uint64(1), detail.NormalIndex["Name"])
+		require.Equal(t, uint64(1), detail.UniqueIndex["Phone"])
+
+		detail, err = db.Snoop(&pb.Order{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), detail.TotalCount)
+		require.Equal(t, uint64(1), detail.UnionIndexCount)
+		require.Equal(t, uint64(1), detail.NormalIndex["CounterId"])
+
+		//expired unique key can be inserted again
+		err = db.Insert(&pb.Person{Name: "jacky", Phone: "+861", Age: 30})
+		require.NoError(t, err)
+	})
+}
+
+func TestTTLRoundUp(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		for i := 0; i < 20; i++ {
+			err = db.InsertWithTTL(&pb.Person{Name: "jacky", Phone: fmt.Sprint(i)}, 300*time.Millisecond)
+			require.NoError(t, err)
+		}
+		count, err := db.Count(&pb.Person{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(20), count)
+
+		at := time.Unix(100, 0)
+		require.Equal(t, uint64(100), expiresAtOf(at))
+		require.Equal(t, uint64(101), expiresAtOf(at.Add(time.Nanosecond)))
+	})
+}
+
+func TestDropTable(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.CreateTable(&pb.Order{})
+		require.NoError(t, err)
+		for i := 0; i < 10; i++ {
+			err = db.Insert(&pb.Person{Name: "jacky", Phone: fmt.Sprintf("+86%d", i), Age: 30})
+			require.NoError(t, err)
+			err = db.Insert(&pb.Order{AccountChannel: "lb", Aaid: uint64(i), OrderId: fmt.Sprint(i), CounterId: "ST/HK/700"})
+			require.NoError(t, err)
+		}
+
+		tables, err := db.Tables()
+		require.NoError(t, err)
+		require.Equal(t, 2, len(tables))
+		require.Equal(t, "Person", tables[0].Name)
+		require.Equal(t, uint64(10), tables[0].RowCount)
+		require.Equal(t, []IndexInfo{
+			{Name: "Name", Type: NORMAL, Fields: []string{"Name"}},
+			{Name: "Phone", Type: UNIQUE, Fields: []string{"Phone"}, Unique: true},
+			{Name: "Age", Type: NORMAL, Fields: []string{"Age"}},
+		}, tables[0].Indexes)
+		require.Equal(t, "Order", tables[1].Name)
+		require.Equal(t, IndexInfo{Name: "union", Type: UNION, Fields: []string{"AccountChannel", "Aaid", "OrderId"}, Unique: true}, tables[1].Indexes[len(tables[1].Indexes)-1])
+
+		err = db.DropTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.DropTable(&pb.Person{})
+		require.ErrorIs(t, err, ErrTableNotFound)
+		_, err = Find(db, WithAnd(&pb.Person{}).Eq("Name", "jacky"))
+		require.ErrorIs(t, err, ErrTableNotFound)
+
+		//table ids are not reused, old keys are gone
+		err = db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		detail, err := db.Snoop(&pb.Person{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), detail.TotalCount)
+		require.Equal(t, uint64(0), detail.UniqueIndex["Phone"])
+		detail, err = db.Snoop(&pb.Order{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), detail.TotalCount)
+		require.Equal(t, uint64(10), detail.UnionIndexCount)
+
+		tables, err = db.Tables()
+		require.NoError(t, err)
+		require.Equal(t, "Order", tables[0].Name)
+		require.Equal(t, "Person", tables[1].Name)
+		require.Equal(t, uint32(2), tables[1].Id)
+	})
+}
+
+func TestRenameTable(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.CreateTable(&pb.Order{})
+		require.NoError(t, err)
+		err = db.Insert(&pb.Person{Name: "jacky", Phone: "+861", Age: 30})
+		require.NoError(t, err)
+
+		err = db.RenameTable("Person", "Order")
+		require.ErrorIs(t, err, ErrTableRepeat)
+		err = db.RenameTable("Account", "Person_v2")
+		require.ErrorIs(t, err, ErrTableNotFound)
+		err = db.RenameTable("Person", "Person_v2")
+		require.NoError(t, err)
+
+		count, err := db.Count(&pb.Person{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), count)
+		person, err := First(db, WithAnd(&pb.Person{}).Eq("Phone", "+861"))
+		require.NoError(t, err)
+		require.Equal(t, "jacky", person.Name)
+		err = db.Insert(&pb.Person{Name: "jim", Phone: "+862", Age: 31})
+		require.NoError(t, err)
+		tables, err := db.Tables()
+		require.NoError(t, err)
+		require.Equal(t, "Person_v2", tables[0].Name)
+		require.Equal(t, uint64(2), tables[0].RowCount)
+		//the type stays registered under the new name
+		err = db.CreateTable(&pb.Person{})
+		require.ErrorIs(t, err, ErrTableRepeat)
+
+		err = db.RenameTable("Person_v2", "Person")
+		require.NoError(t, err)
+		count, err = db.Count(&pb.Person{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), count)
+	})
+}
+
+func TestPathIndex(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.AccountInfo{}, WithPathIndex("AccountProperties.Currency", NORMAL))
+		require.ErrorIs(t, err, ErrFieldNotFound)
+		err = db.CreateTable(&pb.AccountInfo{}, WithPathIndex("AccountProperties", NORMAL))
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+		err = db.CreateTable(&pb.AccountInfo{}, WithPathIndex("AccountProperties.MainCurrency", NORMAL), WithPathIndex("CashBooks", NORMAL))
+		require.NoError(t, err)
+
+		currencies := []string{"HKD", "USD", "CNH"}
+		for i := 0; i < 6; i++ {
+			account := &pb.AccountInfo{
+				AccountChannel: "lb",
+				Aaid:           uint64(i),
+				CashBooks:      map[string]*pb.Detail{},
+				StockBooks:     map[string]*pb.Detail{"700.HK": {UnitOnHand: fmt.Sprint(i % 2)}},
+			}
+			for j := 0; j <= i%3; j++ {
+				account.CashBooks[currencies[j]] = &pb.Detail{}
+			}
+			if i > 0 {
+				account.AccountProperties = &pb.AccountProperties{MainCurrency: currencies[i%3]}
+			}
+			err = db.Insert(account)
+			require.NoError(t, err)
+		}
+
+		count, err := Count(db, WithAnd(&pb.AccountInfo{}).Eq("AccountProperties.MainCurrency", "USD"))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		count, err = Count(db, WithAnd(&pb.AccountInfo{}).Eq("CashBooks", "USD"))
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+		results, err := Find(db, WithAnd(&pb.AccountInfo{}).Eq("CashBooks", "CNH").Eq("AccountProperties.MainCurrency", "CNH"))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(results))
+
+		err = db.Update(results[0].Id, &pb.AccountInfo{AccountChannel: "lb", Aaid: results[0].Aaid, CashBooks: map[string]*pb.Detail{"HKD": {}}})
+		require.NoError(t, err)
+		err = db.Delete(results[1].Id, &pb.AccountInfo{})
+		require.NoError(t, err)
+		count, err = Count(db, WithAnd(&pb.AccountInfo{}).Eq("CashBooks", "CNH"))
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+		count, err = Count(db, WithAnd(&pb.AccountInfo{}).Eq("CashBooks", "HKD"))
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+
+		err = db.CreateIndex(&pb.AccountInfo{}, "StockBooks.UnitOnHand", NORMAL)
+		require.NoError(t, err)
+		err = db.CreateIndex(&pb.AccountInfo{}, "CashBooks", NORMAL)
+		require.ErrorIs(t, err, ErrIdxRepeat)
+		count, err = Count(db, WithAnd(&pb.AccountInfo{}).Eq("StockBooks.UnitOnHand", "1"))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		tables, err := db.Tables()
+		require.NoError(t, err)
+		require.Equal(t, []string{"AccountProperties.MainCurrency", "CashBooks", "StockBooks.UnitOnHand"}, []string{
+			tables[0].Indexes[2].Name, tables[0].Indexes[3].Name, tables[0].Indexes[4].Name,
+		})
+		err = db.DropIndex(&pb.AccountInfo{}, "StockBooks.UnitOnHand")
+		require.NoError(t, err)
+		_, err = Find(db, WithAnd(&pb.AccountInfo{}).Eq("StockBooks.UnitOnHand", "1"))
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+	})
+}
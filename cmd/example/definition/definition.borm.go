@@ -0,0 +1,44 @@
+// Code generated by protoc-gen-borm. DO NOT EDIT.
+// source: definition.proto
+
+package definition
+
+import "github.com/longbridgeapp/borm/typed"
+
+func (*Account) GetTableName() string {
+	return "Account"
+}
+
+func (*Account) Clone() any {
+	return &Account{}
+}
+
+// field names of Account
+const (
+	AccountFieldId          = "Id"
+	AccountFieldName        = "Name"
+	AccountFieldIdentityId  = "IdentityId"
+	AccountFieldPhoneNumber = "PhoneNumber"
+	AccountFieldCountry     = "Country"
+	AccountFieldAge         = "Age"
+	AccountFieldGender      = "Gender"
+)
+
+// AccountQ typed query builders of Account for borm.Where
+var AccountQ = struct {
+	Id          typed.Field[*Account, uint64]
+	Name        typed.StringField[*Account]
+	IdentityId  typed.StringField[*Account]
+	PhoneNumber typed.StringField[*Account]
+	Country     typed.StringField[*Account]
+	Age         typed.Field[*Account, uint32]
+	Gender      typed.Field[*Account, Gender]
+}{
+	Id:          typed.NewField[*Account, uint64](AccountFieldId),
+	Name:        typed.NewStringField[*Account](AccountFieldName),
+	IdentityId:  typed.NewStringField[*Account](AccountFieldIdentityId),
+	PhoneNumber: typed.NewStringField[*Account](AccountFieldPhoneNumber),
+	Country:     typed.NewStringField[*Account](AccountFieldCountry),
+	Age:         typed.NewField[*Account, uint32](AccountFieldAge),
+	Gender:      typed.NewField[*Account, Gender](AccountFieldGender),
+}
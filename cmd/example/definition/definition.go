@@ -1,9 +0,0 @@
-package definition
-
-func (*Account) GetTableName() string {
-	return "Account"
-}
-
-func (*Account) Clone() any {
-	return &Account{}
-}
@@ -104,6 +104,19 @@ func multipleConditionQuery(db *borm.BormDb) {
 	log.Printf("accounts info:%+v", accounts)
 }
 
+func typedQuery(db *borm.BormDb) {
+	//field names and value types are checked at compile time
+	accounts, err := borm.Find(db, borm.Where(&definition.Account{},
+		definition.AccountQ.Name.Eq("jacky"),
+		definition.AccountQ.Age.In(30, 31),
+		definition.AccountQ.PhoneNumber.StartsWith("+86"),
+	).SortBy(true, definition.AccountFieldAge))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("accounts info:%+v", accounts)
+}
+
 func transaction(db *borm.BormDb) {
 	//transaction begin
 	tx := db.Begin(true)
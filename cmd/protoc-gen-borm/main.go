@@ -0,0 +1,284 @@
+//protoc-gen-borm
+//generate GetTableName and Clone of the table messages, the field name constants and the typed
+//query builders, a top level message is a table when its first field is `id` or a field has
+//an idx tag, run it next to gogo:
+//
+//	protoc --proto_path=$GOPATH/src:. --gofast_out=. --borm_out=. pb.proto
+//
+//the query builders import github.com/longbridgeapp/borm/typed, which does not import borm, so the
+//message package can be used by borm itself, the parameter queries=false skips them
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/gogo/protobuf/gogoproto"
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	"github.com/gogo/protobuf/protoc-gen-gogo/generator"
+	plugin "github.com/gogo/protobuf/protoc-gen-gogo/plugin"
+)
+
+const typedImportPath = "github.com/longbridgeapp/borm/typed"
+
+var scalarGoTypes = map[descriptor.FieldDescriptorProto_Type]string{
+	descriptor.FieldDescriptorProto_TYPE_DOUBLE:   "float64",
+	descriptor.FieldDescriptorProto_TYPE_FLOAT:    "float32",
+	descriptor.FieldDescriptorProto_TYPE_INT64:    "int64",
+	descriptor.FieldDescriptorProto_TYPE_SINT64:   "int64",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED64: "int64",
+	descriptor.FieldDescriptorProto_TYPE_UINT64:   "uint64",
+	descriptor.FieldDescriptorProto_TYPE_FIXED64:  "uint64",
+	descriptor.FieldDescriptorProto_TYPE_INT32:    "int32",
+	descriptor.FieldDescriptorProto_TYPE_SINT32:   "int32",
+	descriptor.FieldDescriptorProto_TYPE_SFIXED32: "int32",
+	descriptor.FieldDescriptorProto_TYPE_UINT32:   "uint32",
+	descriptor.FieldDescriptorProto_TYPE_FIXED32:  "uint32",
+	descriptor.FieldDescriptorProto_TYPE_BOOL:     "bool",
+	descriptor.FieldDescriptorProto_TYPE_STRING:   "string",
+	descriptor.FieldDescriptorProto_TYPE_BYTES:    "[]byte",
+}
+
+type options struct {
+	queries bool
+}
+
+func main() {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		fail(err)
+	}
+	req := &plugin.CodeGeneratorRequest{}
+	if err = proto.Unmarshal(data, req); err != nil {
+		fail(err)
+	}
+	resp := generate(req)
+	data, err = proto.Marshal(resp)
+	if err != nil {
+		fail(err)
+	}
+	if _, err = os.Stdout.Write(data); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "protoc-gen-borm: %v\n", err)
+	os.Exit(1)
+}
+
+func parseOptions(parameter string) (*options, error) {
+	opts := &options{queries: true}
+	for _, param := range strings.Split(parameter, ",") {
+		if param == "" {
+			continue
+		}
+		kv := strings.SplitN(param, "=", 2)
+		switch {
+		case len(kv) == 2 && kv[0] == "queries":
+			opts.queries = kv[1] != "false"
+		default:
+			return nil, fmt.Errorf("unknown parameter %q", param)
+		}
+	}
+	return opts, nil
+}
+
+func generate(req *plugin.CodeGeneratorRequest) *plugin.CodeGeneratorResponse {
+	resp := &plugin.CodeGeneratorResponse{}
+	opts, err := parseOptions(req.GetParameter())
+	if err != nil {
+		resp.Error = proto.String(err.Error())
+		return resp
+	}
+	files := map[string]*descriptor.FileDescriptorProto{}
+	for _, file := range req.ProtoFile {
+		files[file.GetName()] = file
+	}
+	for _, name := range req.FileToGenerate {
+		file := files[name]
+		content, ok, err := generateFile(file, opts)
+		if err != nil {
+			resp.Error = proto.String(fmt.Sprintf("%s: %v", name, err))
+			return resp
+		}
+		if !ok {
+			continue
+		}
+		resp.File = append(resp.File, &plugin.CodeGeneratorResponse_File{
+			Name:    proto.String(outputName(file)),
+			Content: proto.String(content),
+		})
+	}
+	return resp
+}
+
+//goPackage
+//the import path and package name of the generated code, like protoc-gen-gogo
+func goPackage(file *descriptor.FileDescriptorProto) (string, string) {
+	goPkg := file.GetOptions().GetGoPackage()
+	if goPkg == "" {
+		return "", strings.ReplaceAll(file.GetPackage(), ".", "_")
+	}
+	if i := strings.Index(goPkg, ";"); i >= 0 {
+		return goPkg[:i], goPkg[i+1:]
+	}
+	return goPkg, path.Base(goPkg)
+}
+
+func outputName(file *descriptor.FileDescriptorProto) string {
+	name := strings.TrimSuffix(file.GetName(), ".proto") + ".borm.go"
+	importPath, _ := goPackage(file)
+	if strings.Contains(importPath, "/") {
+		return path.Join(importPath, path.Base(name))
+	}
+	return name
+}
+
+func isTable(message *descriptor.DescriptorProto) bool {
+	if len(message.Field) == 0 {
+		return false
+	}
+	if message.Field[0].GetName() == "id" {
+		return true
+	}
+	for _, field := range message.Field {
+		if strings.Contains(moreTags(field), `idx:"`) {
+			return true
+		}
+	}
+	return false
+}
+
+func moreTags(field *descriptor.FieldDescriptorProto) string {
+	if tags := gogoproto.GetMoreTags(field); tags != nil {
+		return *tags
+	}
+	return ""
+}
+
+func fieldGoName(field *descriptor.FieldDescriptorProto) string {
+	if gogoproto.IsCustomName(field) {
+		return gogoproto.GetCustomName(field)
+	}
+	return generator.CamelCase(field.GetName())
+}
+
+//queryType
+//the typed field type of a field, "" when the field can not be queried by value
+func queryType(file *descriptor.FileDescriptorProto, message string, field *descriptor.FieldDescriptorProto) string {
+	if field.GetLabel() == descriptor.FieldDescriptorProto_LABEL_REPEATED || field.OneofIndex != nil {
+		return ""
+	}
+	if gogoproto.IsEmbed(field) || gogoproto.IsCustomType(field) || gogoproto.IsCastType(field) || gogoproto.IsStdType(field) {
+		return ""
+	}
+	//proto2 scalars are pointers
+	if !gogoproto.IsProto3(file) && gogoproto.IsNullable(field) {
+		return ""
+	}
+	row := "*" + message
+	if field.GetType() == descriptor.FieldDescriptorProto_TYPE_STRING {
+		return fmt.Sprintf("typed.StringField[%s]", row)
+	}
+	if goType, ok := scalarGoTypes[field.GetType()]; ok {
+		return fmt.Sprintf("typed.Field[%s, %s]", row, goType)
+	}
+	if field.GetType() == descriptor.FieldDescriptorProto_TYPE_ENUM {
+		prefix := "." + file.GetPackage() + "."
+		if file.GetPackage() == "" {
+			prefix = "."
+		}
+		if !strings.HasPrefix(field.GetTypeName(), prefix) {
+			return ""
+		}
+		enum := generator.CamelCaseSlice(strings.Split(strings.TrimPrefix(field.GetTypeName(), prefix), "."))
+		return fmt.Sprintf("typed.Field[%s, %s]", row, enum)
+	}
+	return ""
+}
+
+func queryConstructor(goType string) string {
+	if strings.HasPrefix(goType, "typed.StringField") {
+		return strings.Replace(goType, "typed.StringField", "typed.NewStringField", 1)
+	}
+	return strings.Replace(goType, "typed.Field", "typed.NewField", 1)
+}
+
+func generateFile(file *descriptor.FileDescriptorProto, opts *options) (string, bool, error) {
+	tables := []*descriptor.DescriptorProto{}
+	for _, message := range file.MessageType {
+		if isTable(message) {
+			tables = append(tables, message)
+		}
+	}
+	if len(tables) == 0 {
+		return "", false, nil
+	}
+	_, pkg := goPackage(file)
+	buf := &bytes.Buffer{}
+	p := func(format string, args ...any) {
+		fmt.Fprintf(buf, format+"\n", args...)
+	}
+	p("// Code generated by protoc-gen-borm. DO NOT EDIT.")
+	p("// source: %s", file.GetName())
+	p("")
+	p("package %s", pkg)
+	if opts.queries {
+		p("")
+		p("import %q", typedImportPath)
+	}
+	for _, message := range tables {
+		name := generator.CamelCase(message.GetName())
+		p("")
+		p("func (*%s) GetTableName() string {", name)
+		p("return %q", name)
+		p("}")
+		p("")
+		p("func (*%s) Clone() any {", name)
+		p("return &%s{}", name)
+		p("}")
+		p("")
+		p("//field names of %s", name)
+		p("const (")
+		for _, field := range message.Field {
+			goName := fieldGoName(field)
+			p("%sField%s = %q", name, goName, goName)
+		}
+		p(")")
+		if !opts.queries {
+			continue
+		}
+		type queryField struct {
+			name, goType string
+		}
+		fields := []queryField{}
+		for _, field := range message.Field {
+			if goType := queryType(file, name, field); goType != "" {
+				fields = append(fields, queryField{name: fieldGoName(field), goType: goType})
+			}
+		}
+		p("")
+		p("//%sQ typed query builders of %s for borm.Where", name, name)
+		p("var %sQ = struct {", name)
+		for _, field := range fields {
+			p("%s %s", field.name, field.goType)
+		}
+		p("}{")
+		for _, field := range fields {
+			p("%s: %s(%sField%s),", field.name, queryConstructor(field.goType), name, field.name)
+		}
+		p("}")
+	}
+	content, err := format.Source(buf.Bytes())
+	if err != nil {
+		return "", false, err
+	}
+	return string(content), true, nil
+}
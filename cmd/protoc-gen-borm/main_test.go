@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/gogo/protobuf/proto"
+	"github.com/gogo/protobuf/protoc-gen-gogo/descriptor"
+	plugin "github.com/gogo/protobuf/protoc-gen-gogo/plugin"
+	golangproto "github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/require"
+
+	_ "github.com/longbridgeapp/borm/pb"
+)
+
+//registeredFile
+//the descriptor of a proto file registered by its generated package, so the test needs no protoc
+func registeredFile(t *testing.T, name string) *descriptor.FileDescriptorProto {
+	gz := golangproto.FileDescriptor(name)
+	require.NotNil(t, gz)
+	r, err := gzip.NewReader(bytes.NewReader(gz))
+	require.Nil(t, err)
+	data, err := io.ReadAll(r)
+	require.Nil(t, err)
+	file := &descriptor.FileDescriptorProto{}
+	require.Nil(t, proto.Unmarshal(data, file))
+	return file
+}
+
+//TestGenerateGolden
+//pb/pb.borm.go is generated with the query builders, see pb/makefile
+func TestGenerateGolden(t *testing.T) {
+	req := &plugin.CodeGeneratorRequest{
+		FileToGenerate: []string{"pb.proto"},
+		ProtoFile:      []*descriptor.FileDescriptorProto{registeredFile(t, "pb.proto")},
+	}
+	resp := generate(req)
+	require.Empty(t, resp.GetError())
+	require.Len(t, resp.File, 1)
+	require.Equal(t, "pb.borm.go", resp.File[0].GetName())
+	golden, err := os.ReadFile("../../pb/pb.borm.go")
+	require.Nil(t, err)
+	require.Equal(t, string(golden), resp.File[0].GetContent())
+	require.Contains(t, resp.File[0].GetContent(), `import "github.com/longbridgeapp/borm/typed"`)
+	require.Contains(t, resp.File[0].GetContent(), "AccountChannel: typed.NewStringField[*Order](OrderFieldAccountChannel),")
+
+	req.Parameter = proto.String("queries=false")
+	resp = generate(req)
+	require.Empty(t, resp.GetError())
+	require.NotContains(t, resp.File[0].GetContent(), "import")
+	require.NotContains(t, resp.File[0].GetContent(), "OrderQ")
+	require.Contains(t, resp.File[0].GetContent(), "OrderFieldAccountChannel")
+
+	req.Parameter = proto.String("unknown=1")
+	require.Contains(t, generate(req).GetError(), "unknown parameter")
+}
@@ -0,0 +1,334 @@
+package borm
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"reflect"
+)
+
+//Codec
+//encode and decode the rows of a table, set by WithCodec
+type Codec interface {
+	Marshal(row any) ([]byte, error)
+	Unmarshal(data []byte, row any) error
+}
+
+//JSONCodec
+//rows are stored as encoding/json documents, a StructRow as the document of its struct
+type JSONCodec struct{}
+
+func (JSONCodec) Marshal(row any) ([]byte, error) {
+	return json.Marshal(rowTarget(row))
+}
+
+func (JSONCodec) Unmarshal(data []byte, row any) error {
+	return json.Unmarshal(data, rowTarget(row))
+}
+
+//messageCodec
+//the default codec, rows encode themselves
+type messageCodec struct{}
+
+func (messageCodec) Marshal(row any) ([]byte, error) {
+	msg, ok := row.(IRow)
+	if !ok {
+		return nil, ErrCodecNotSupport
+	}
+	return msg.Marshal()
+}
+
+func (messageCodec) Unmarshal(data []byte, row any) error {
+	msg, ok := row.(IRow)
+	if !ok {
+		return ErrCodecNotSupport
+	}
+	return msg.Unmarshal(data)
+}
+
+//ReflectCodec
+//the default codec of StructRow, a compact binary encoding of the exported fields
+//in declaration order, adding, removing or reordering fields breaks the stored rows,
+//types implementing encoding.BinaryMarshaler like time.Time encode themselves
+type ReflectCodec struct{}
+
+var (
+	binaryMarshalerType   = reflect.TypeOf((*encoding.BinaryMarshaler)(nil)).Elem()
+	binaryUnmarshalerType = reflect.TypeOf((*encoding.BinaryUnmarshaler)(nil)).Elem()
+)
+
+func (ReflectCodec) Marshal(row any) ([]byte, error) {
+	v := reflect.ValueOf(rowTarget(row))
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return nil, ErrCodecNotSupport
+	}
+	return appendReflectValue(nil, v.Elem())
+}
+
+func (ReflectCodec) Unmarshal(data []byte, row any) error {
+	v := reflect.ValueOf(rowTarget(row))
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return ErrCodecNotSupport
+	}
+	v.Elem().Set(reflect.Zero(v.Elem().Type()))
+	d := &reflectDecoder{data: data}
+	return d.decode(v.Elem())
+}
+
+func isBinaryCodable(t reflect.Type) bool {
+	return t.Kind() != reflect.Ptr && t.Implements(binaryMarshalerType) && reflect.PtrTo(t).Implements(binaryUnmarshalerType)
+}
+
+func appendUvarint(buf []byte, x uint64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutUvarint(b[:], x)]...)
+}
+
+func appendVarint(buf []byte, x int64) []byte {
+	var b [binary.MaxVarintLen64]byte
+	return append(buf, b[:binary.PutVarint(b[:], x)]...)
+}
+
+func appendReflectValue(buf []byte, v reflect.Value) ([]byte, error) {
+	if isBinaryCodable(v.Type()) {
+		bs, err := v.Interface().(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		buf = appendUvarint(buf, uint64(len(bs)))
+		return append(buf, bs...), nil
+	}
+	var err error
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return appendVarint(buf, v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return appendUvarint(buf, v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		var b [8]byte
+		binary.BigEndian.PutUint64(b[:], math.Float64bits(v.Float()))
+		return append(buf, b[:]...), nil
+	case reflect.String:
+		buf = appendUvarint(buf, uint64(v.Len()))
+		return append(buf, v.String()...), nil
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			buf = appendUvarint(buf, uint64(v.Len()))
+			return append(buf, v.Bytes()...), nil
+		}
+		buf = appendUvarint(buf, uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			if buf, err = appendReflectValue(buf, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if buf, err = appendReflectValue(buf, v.Index(i)); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case reflect.Map:
+		buf = appendUvarint(buf, uint64(v.Len()))
+		it := v.MapRange()
+		for it.Next() {
+			if buf, err = appendReflectValue(buf, it.Key()); err != nil {
+				return nil, err
+			}
+			if buf, err = appendReflectValue(buf, it.Value()); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case reflect.Ptr:
+		if v.IsNil() {
+			return append(buf, 0), nil
+		}
+		return appendReflectValue(append(buf, 1), v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if buf, err = appendReflectValue(buf, v.Field(i)); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+	return nil, ErrCodecNotSupport
+}
+
+type reflectDecoder struct {
+	data []byte
+	pos  int
+}
+
+func (d *reflectDecoder) uvarint() (uint64, error) {
+	x, n := binary.Uvarint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	d.pos += n
+	return x, nil
+}
+
+func (d *reflectDecoder) varint() (int64, error) {
+	x, n := binary.Varint(d.data[d.pos:])
+	if n <= 0 {
+		return 0, io.ErrUnexpectedEOF
+	}
+	d.pos += n
+	return x, nil
+}
+
+func (d *reflectDecoder) next(n uint64) ([]byte, error) {
+	if uint64(len(d.data)-d.pos) < n {
+		return nil, io.ErrUnexpectedEOF
+	}
+	bs := d.data[d.pos : d.pos+int(n)]
+	d.pos += int(n)
+	return bs, nil
+}
+
+//length
+//a length prefix, bounded by the remaining bytes so corrupt data can not allocate huge slices
+func (d *reflectDecoder) length() (int, error) {
+	n, err := d.uvarint()
+	if err != nil {
+		return 0, err
+	}
+	if n > uint64(len(d.data)-d.pos) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	return int(n), nil
+}
+
+func (d *reflectDecoder) decode(v reflect.Value) error {
+	if isBinaryCodable(v.Type()) {
+		n, err := d.length()
+		if err != nil {
+			return err
+		}
+		bs, err := d.next(uint64(n))
+		if err != nil {
+			return err
+		}
+		return v.Addr().Interface().(encoding.BinaryUnmarshaler).UnmarshalBinary(bs)
+	}
+	switch v.Kind() {
+	case reflect.Bool:
+		bs, err := d.next(1)
+		if err != nil {
+			return err
+		}
+		v.SetBool(bs[0] != 0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, err := d.varint()
+		if err != nil {
+			return err
+		}
+		v.SetInt(x)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		x, err := d.uvarint()
+		if err != nil {
+			return err
+		}
+		v.SetUint(x)
+	case reflect.Float32, reflect.Float64:
+		bs, err := d.next(8)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(math.Float64frombits(binary.BigEndian.Uint64(bs)))
+	case reflect.String:
+		n, err := d.length()
+		if err != nil {
+			return err
+		}
+		bs, err := d.next(uint64(n))
+		if err != nil {
+			return err
+		}
+		v.SetString(string(bs))
+	case reflect.Slice:
+		n, err := d.length()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			bs, err := d.next(uint64(n))
+			if err != nil {
+				return err
+			}
+			v.SetBytes(append([]byte(nil), bs...))
+			return nil
+		}
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		for i := 0; i < n; i++ {
+			if err := d.decode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if err := d.decode(v.Index(i)); err != nil {
+				return err
+			}
+		}
+	case reflect.Map:
+		n, err := d.length()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		v.Set(reflect.MakeMapWithSize(v.Type(), n))
+		for i := 0; i < n; i++ {
+			key := reflect.New(v.Type().Key()).Elem()
+			if err := d.decode(key); err != nil {
+				return err
+			}
+			val := reflect.New(v.Type().Elem()).Elem()
+			if err := d.decode(val); err != nil {
+				return err
+			}
+			v.SetMapIndex(key, val)
+		}
+	case reflect.Ptr:
+		bs, err := d.next(1)
+		if err != nil {
+			return err
+		}
+		if bs[0] == 0 {
+			return nil
+		}
+		v.Set(reflect.New(v.Type().Elem()))
+		return d.decode(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Type().Field(i).IsExported() {
+				continue
+			}
+			if err := d.decode(v.Field(i)); err != nil {
+				return err
+			}
+		}
+	default:
+		return ErrCodecNotSupport
+	}
+	return nil
+}
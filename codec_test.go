@@ -0,0 +1,134 @@
+package borm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/longbridgeapp/borm/pb"
+
+	"github.com/stretchr/testify/require"
+)
+
+type plainQuote struct {
+	Id        uint64
+	Symbol    string `idx:"unique"`
+	Market    string `idx:"normal"`
+	Price     float64
+	Tags      []string
+	Extra     map[string]int32
+	Parent    *plainQuote
+	UpdatedAt time.Time
+	note      string
+}
+
+func TestStructTable(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&StructRow[struct{ Id uint64 }]{})
+		require.ErrorIs(t, err, ErrTableNameEmpty)
+		err = db.CreateTable(&StructRow[int]{})
+		require.ErrorIs(t, err, ErrRowTypeIllegal)
+		err = db.CreateTable(&StructRow[plainQuote]{}, WithTableName("Quote"))
+		require.NoError(t, err)
+		err = db.CreateTable(&StructRow[plainQuote]{}, WithTableName("Quote_2"))
+		require.ErrorIs(t, err, ErrTableRepeat)
+		err = db.CreateTable(&pb.Person{}, WithTableName("JsonPerson"), WithCodec(JSONCodec{}))
+		require.NoError(t, err)
+
+		updatedAt := time.Date(2022, 6, 1, 9, 30, 0, 0, time.UTC)
+		quote := &StructRow[plainQuote]{Row: plainQuote{
+			Symbol:    "700.HK",
+			Market:    "HK",
+			Price:     351.2,
+			Tags:      []string{"tech", "hsi"},
+			Extra:     map[string]int32{"lot": 100},
+			Parent:    &plainQuote{Symbol: "HSI"},
+			UpdatedAt: updatedAt,
+			note:      "not stored",
+		}}
+		err = db.Insert(quote)
+		require.NoError(t, err)
+		id, err := db.GetRowId(quote)
+		require.NoError(t, err)
+		require.Equal(t, quote.Row.Id, id)
+		err = db.Insert(&StructRow[plainQuote]{Row: plainQuote{Symbol: "AAPL.US", Market: "US", Price: 150}})
+		require.NoError(t, err)
+		err = db.Insert(&StructRow[plainQuote]{Row: plainQuote{Symbol: "700.HK", Market: "HK"}})
+		require.ErrorIs(t, err, ErrIdxUniqueConflict)
+
+		result, err := First(db, WithAnd(&StructRow[plainQuote]{}).Eq("Symbol", "700.HK"))
+		require.NoError(t, err)
+		require.Equal(t, quote.Row.Id, result.Row.Id)
+		require.Equal(t, 351.2, result.Row.Price)
+		require.Equal(t, []string{"tech", "hsi"}, result.Row.Tags)
+		require.Equal(t, map[string]int32{"lot": 100}, result.Row.Extra)
+		require.Equal(t, "HSI", result.Row.Parent.Symbol)
+		require.True(t, updatedAt.Equal(result.Row.UpdatedAt))
+		require.Equal(t, "", result.Row.note)
+
+		err = db.Update(result.Row.Id, &StructRow[plainQuote]{Row: plainQuote{Symbol: "700.HK", Market: "HKEX", Price: 352}})
+		require.NoError(t, err)
+		count, err := Count(db, WithAnd(&StructRow[plainQuote]{}).Eq("Market", "HKEX"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		detail, err := db.Snoop(&StructRow[plainQuote]{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), detail.TotalCount)
+		require.Equal(t, uint64(2), detail.UniqueIndex["Symbol"])
+		err = db.Delete(result.Row.Id, &StructRow[plainQuote]{})
+		require.NoError(t, err)
+		total, err := db.Count(&StructRow[plainQuote]{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), total)
+
+		err = db.RenameTable("Quote", "Quote_v2")
+		require.NoError(t, err)
+		total, err = db.Count(&StructRow[plainQuote]{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), total)
+
+		//the message is stored as json in the table named by WithTableName
+		err = db.Insert(&pb.Person{Name: "jacky", Phone: "+861", Age: 30})
+		require.NoError(t, err)
+		person, err := First(db, WithAnd(&pb.Person{}).Eq("Name", "jacky"))
+		require.NoError(t, err)
+		require.Equal(t, uint32(30), person.Age)
+		tables, err := db.Tables()
+		require.NoError(t, err)
+		require.Equal(t, "JsonPerson", tables[1].Name)
+		require.Equal(t, uint64(1), tables[1].RowCount)
+
+		err = db.DropTable(&StructRow[plainQuote]{})
+		require.NoError(t, err)
+		//named after the struct without WithTableName, json holds the struct itself
+		err = db.CreateTable(&StructRow[plainQuote]{}, WithCodec(JSONCodec{}))
+		require.NoError(t, err)
+		err = db.Insert(&StructRow[plainQuote]{Row: plainQuote{Symbol: "AAPL.US"}})
+		require.NoError(t, err)
+		tables, err = db.Tables()
+		require.NoError(t, err)
+		require.Equal(t, "plainQuote", tables[1].Name)
+		bs, err := JSONCodec{}.Marshal(&StructRow[plainQuote]{Row: plainQuote{Symbol: "AAPL.US"}})
+		require.NoError(t, err)
+		require.Contains(t, string(bs), `"Symbol":"AAPL.US"`)
+		require.NotContains(t, string(bs), `"Row"`)
+	})
+}
+
+func TestReflectCodec(t *testing.T) {
+	codec := ReflectCodec{}
+	quote := &plainQuote{Id: 1, Symbol: "700.HK", Price: -1.5, Tags: []string{}, UpdatedAt: time.Unix(1654000000, 5).UTC()}
+	bs, err := codec.Marshal(quote)
+	require.NoError(t, err)
+	decoded := &plainQuote{Market: "dirty"}
+	err = codec.Unmarshal(bs, decoded)
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), decoded.Id)
+	require.Equal(t, "", decoded.Market)
+	require.Equal(t, -1.5, decoded.Price)
+	require.Nil(t, decoded.Parent)
+	require.True(t, quote.UpdatedAt.Equal(decoded.UpdatedAt))
+	err = codec.Unmarshal(bs[:len(bs)-1], decoded)
+	require.Error(t, err)
+	_, err = codec.Marshal(&struct{ Ch chan int }{})
+	require.ErrorIs(t, err, ErrCodecNotSupport)
+}
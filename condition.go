@@ -1,9 +1,11 @@
 package borm
 
 import (
+	"bytes"
 	"fmt"
 	"math/big"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/longbridgeapp/borm/common"
@@ -15,23 +17,45 @@ import (
 type ICompoundConditions[T IRow] interface {
 	Eq(fieldName string, val any) ICompoundConditions[T]
 	In(fieldNames []string, values [][]any) ICompoundConditions[T]
+	StartsWith(fieldName string, prefix string) ICompoundConditions[T]
+	Like(fieldName string, pattern string) ICompoundConditions[T]
+	Match(fieldName string, query string) ICompoundConditions[T]
+	SortByRelevance() ICompoundConditions[T]
 	SortBy(reversed bool, sortKey ...string) ICompoundConditions[T]
 	Limit(offset, limit int) ICompoundConditions[T]
 	query(txn *badger.Txn, db *BormDb) ([]T, error)
 	count(txn *badger.Txn, db *BormDb) (int, error)
+	getRow() IRow
+	invalidate()
 }
 
 type inFilterCondition struct {
 	fieldNames []string
 	values     [][]any
 }
+
+type prefixCondition struct {
+	fieldName string
+	prefix    string
+}
+
+type matchCondition struct {
+	fieldName string
+	query     string
+}
+
 type BaseCompoundCondition[T IRow] struct {
 	fieldValueMap      *orderedmap.OrderedMap[string, any]
 	inFilterConditions []inFilterCondition
+	prefixConditions   []prefixCondition
+	matchConditions    []matchCondition
 	row                IRow
 
 	sortKey   []string
 	reverse   bool
+	relevance bool
+	//relevance of the matched rows, summed over the match conditions
+	scores    map[uint64]float64
 	offset    int
 	limit     int
 	validated bool
@@ -52,6 +76,12 @@ func (c *BaseCompoundCondition[T]) getRow() IRow {
 	return c.row
 }
 
+//invalidate
+//the query fails with ErrQueryInvalid
+func (c *BaseCompoundCondition[T]) invalidate() {
+	c.validated = false
+}
+
 func (c *BaseCompoundCondition[T]) CheckValidate() error {
 	if !c.validated {
 		return ErrQueryInvalid
@@ -103,51 +133,106 @@ type fieldKeyValue struct {
 
 func (c *BaseCompoundCondition[T]) subQuery(txn *badger.Txn, db *BormDb, tableId uint32, fieldValues []fieldKeyValue) ([]uint64, error) {
 	uniqueIdxMap := map[uint32]any{}
-	unionIdxMap := map[uint32]any{}
 	normalIdxMap := orderedmap.NewOrderedMap[uint32, any]()
 
+	eqConditions := make(map[string]any, len(fieldValues))
+	for _, fieldValue := range fieldValues {
+		eqConditions[fieldValue.fieldName] = fieldValue.val
+	}
+	//a partial index implied by the conditions holds fewer rows than the full index,
+	//conditions on its predicate field don't need an index of their own
+	partialTags := map[string]*tag{}
+	predicateTags := map[string]*tag{}
+	for _, fieldValue := range fieldValues {
+		if idx, tag, ok := db.tableManager.GetPartialIdx(tableId, fieldValue.fieldName, eqConditions); ok {
+			val, err := tag.normalize(fieldValue.val)
+			if err != nil {
+				return nil, err
+			}
+			if tag.CheckIsUnique() {
+				uniqueIdxMap[idx] = val
+			} else {
+				normalIdxMap.Set(idx, val)
+			}
+			partialTags[fieldValue.fieldName] = tag
+			predicateTags[tag.predicate.tag.fieldName] = tag.predicate.tag
+		}
+	}
+	predicateFilters := []predicateFilter{}
 	for _, fieldValue := range fieldValues {
+		if _, ok := partialTags[fieldValue.fieldName]; ok {
+			continue
+		}
 		idx, err := db.tableManager.GetUniqueIdx(tableId, fieldValue.fieldName)
 		if err != nil {
 			if err == ErrIdxNotSupport {
 				idx, err = db.tableManager.GetNormalIdx(tableId, fieldValue.fieldName)
+				if err == ErrIdxNotSupport && predicateTags[fieldValue.fieldName] != nil {
+					predicateTag := predicateTags[fieldValue.fieldName]
+					val, err := normalizeIndexValue(predicateTag.fieldType, fieldValue.val)
+					if err != nil {
+						return nil, err
+					}
+					predicateFilters = append(predicateFilters, predicateFilter{tag: predicateTag, val: val})
+					continue
+				}
 				if err != nil {
 					return nil, err
 				}
-				normalIdxMap.Set(idx, fieldValue.val)
+				val, err := db.normalizeQueryValue(tableId, idx, fieldValue.val)
+				if err != nil {
+					return nil, err
+				}
+				normalIdxMap.Set(idx, val)
 			} else {
 				return nil, err
 			}
 		} else {
-			uniqueIdxMap[idx] = fieldValue.val
+			val, err := db.normalizeQueryValue(tableId, idx, fieldValue.val)
+			if err != nil {
+				return nil, err
+			}
+			uniqueIdxMap[idx] = val
 		}
 	}
-	//first match unionIdxMap
-	unionTags := db.tableManager.GetUnionTags(tableId)
-	for _, idx := range unionTags {
-		val, ok := normalIdxMap.Get(idx)
-		if ok {
-			unionIdxMap[idx] = val
-		}
+	//first match union indexes by the left prefix of their fields covered by the conditions,
+	//unique and wider ones first, a union is used when all of its fields or a prefix of at
+	//least two fields are covered, fields served by a union are deleted from normalIdxMap
+	eqIdxMap := map[uint32]any{}
+	for idx, val := range uniqueIdxMap {
+		eqIdxMap[idx] = val
 	}
-	//if match union index, delete from normalIdxMap
-	//else set unionIdxMap empty
-	if len(unionIdxMap) == len(unionTags) {
-		for _, idx := range unionTags {
-			normalIdxMap.Delete(idx)
-		}
-	} else {
-		unionIdxMap = nil
+	for _, idx := range normalIdxMap.Keys() {
+		eqIdxMap[idx], _ = normalIdxMap.Get(idx)
 	}
 	arrays := [][]uint64{}
-	if len(unionIdxMap) > 0 {
-		id, err := db.TxQueryWithUnionIndex(txn, c.row, unionIdxMap)
+	coveredIdx := map[uint32]bool{}
+	for _, union := range sortUnionIndexes(db.tableManager.GetUnionIndexes(tableId)) {
+		vals := []any{}
+		uncovered := false
+		for _, idx := range union.fields {
+			val, ok := eqIdxMap[idx]
+			if !ok {
+				break
+			}
+			vals = append(vals, val)
+			uncovered = uncovered || !coveredIdx[idx]
+		}
+		if !uncovered || (len(vals) < len(union.fields) && len(vals) < 2) {
+			continue
+		}
+		for _, idx := range union.fields[:len(vals)] {
+			normalIdxMap.Delete(idx)
+			coveredIdx[idx] = true
+		}
+		ids, err := db.txQueryWithUnion(txn, tableId, union, vals)
 		if err != nil {
 			if err == ErrKeyNotFound {
 				return []uint64{}, nil
 			}
+			return nil, err
 		}
-		arrays = append(arrays, []uint64{id})
+		arrays = append(arrays, ids)
 	}
 
 	for idx, val := range uniqueIdxMap {
@@ -177,7 +262,54 @@ func (c *BaseCompoundCondition[T]) subQuery(txn *badger.Txn, db *BormDb, tableId
 		//left normal index match data
 		arrays = append(arrays, ids)
 	}
-	return common.ArrayIntersection(arrays...), nil
+	ids := common.ArrayIntersection(arrays...)
+	if len(predicateFilters) > 0 {
+		return c.predicateFilterMatch(txn, db, ids, predicateFilters)
+	}
+	return ids, nil
+}
+
+// predicateFilter
+// an eq condition on the unindexed predicate field of a partial index
+type predicateFilter struct {
+	tag *tag
+	val any
+}
+
+func (c *BaseCompoundCondition[T]) predicateFilterMatch(txn *badger.Txn, db *BormDb, ids []uint64, filters []predicateFilter) ([]uint64, error) {
+	matched := make([]uint64, 0, len(ids))
+	err := db.TxQueryWithPk(txn, c.row, ids, func(row IRow) error {
+		for _, filter := range filters {
+			found := false
+			for _, val := range filter.tag.rowValues(row) {
+				if indexValueEqual(val, filter.val) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil
+			}
+		}
+		matched = append(matched, rowId(row))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return matched, nil
+}
+
+func sortUnionIndexes(unionIndexes []*unionIndex) []*unionIndex {
+	sorted := make([]*unionIndex, len(unionIndexes))
+	copy(sorted, unionIndexes)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].unique != sorted[j].unique {
+			return sorted[i].unique
+		}
+		return len(sorted[i].fields) > len(sorted[j].fields)
+	})
+	return sorted
 }
 
 func (c *BaseCompoundCondition[T]) queryRowIds(txn *badger.Txn, db *BormDb) ([]uint64, error) {
@@ -185,7 +317,7 @@ func (c *BaseCompoundCondition[T]) queryRowIds(txn *badger.Txn, db *BormDb) ([]u
 	if err != nil {
 		return nil, err
 	}
-	tableName := c.row.GetTableName()
+	tableName := db.tableManager.GetTableName(c.row)
 	tableId, err := db.tableManager.GetTableId(tableName)
 	if err != nil {
 		return nil, err
@@ -205,15 +337,65 @@ func (c *BaseCompoundCondition[T]) queryRowIds(txn *badger.Txn, db *BormDb) ([]u
 		}
 		intersection = append(intersection, inIds)
 	}
+	for _, prefixCondition := range c.prefixConditions {
+		prefixIds, err := c.queryPrefixRowIds(txn, db, tableId, prefixCondition)
+		if err != nil {
+			return nil, err
+		}
+		intersection = append(intersection, prefixIds)
+	}
+	if c.relevance {
+		c.scores = map[uint64]float64{}
+	}
+	for _, matchCondition := range c.matchConditions {
+		matchIds, err := c.queryMatchRowIds(txn, db, tableId, matchCondition)
+		if err != nil {
+			return nil, err
+		}
+		intersection = append(intersection, matchIds)
+	}
 	queryResults := common.ArrayIntersection(intersection...)
 	return queryResults, nil
 }
 
+func (c *BaseCompoundCondition[T]) queryMatchRowIds(txn *badger.Txn, db *BormDb, tableId uint32, condition matchCondition) ([]uint64, error) {
+	idx, err := db.tableManager.GetFulltextIdx(tableId, condition.fieldName)
+	if err != nil {
+		return nil, err
+	}
+	if !c.relevance {
+		return db.TxQueryWithFulltextIndex(txn, c.row, idx, condition.query)
+	}
+	scores, err := db.TxQueryWithFulltextIndexScores(txn, c.row, idx, condition.query)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(scores))
+	for id, score := range scores {
+		ids = append(ids, id)
+		c.scores[id] += score
+	}
+	return ids, nil
+}
+
+// queryPrefixRowIds
+// seek the unique or normal string index of the field by the prefix
+func (c *BaseCompoundCondition[T]) queryPrefixRowIds(txn *badger.Txn, db *BormDb, tableId uint32, condition prefixCondition) ([]uint64, error) {
+	idx, err := db.tableManager.GetUniqueIdx(tableId, condition.fieldName)
+	if err == ErrIdxNotSupport {
+		idx, err = db.tableManager.GetNormalIdx(tableId, condition.fieldName)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return db.TxQueryWithIndexPrefix(txn, c.row, idx, condition.prefix)
+}
+
 func (c *BaseCompoundCondition[T]) query(txn *badger.Txn, db *BormDb) ([]T, error) {
 	if db.optConfig.QueryAnalyzer {
 		start := time.Now()
 		defer func() {
-			db.optConfig.Logger.Printf("[%v][%s][rows:%v]", time.Since(start), queryAnalyzer(c), c.rows)
+			db.optConfig.Logger.Printf("[%v][%s][rows:%v]", time.Since(start), queryAnalyzer(c, db.tableManager.GetTableName(c.row)), c.rows)
 		}()
 	}
 	ids, err := c.queryRowIds(txn, db)
@@ -238,6 +420,11 @@ func (c *BaseCompoundCondition[T]) query(txn *badger.Txn, db *BormDb) ([]T, erro
 	if err != nil {
 		return nil, err
 	}
+	if c.relevance {
+		sort.SliceStable(results, func(i, j int) bool {
+			return c.scores[rowId(results[i])] > c.scores[rowId(results[j])]
+		})
+	}
 	startIndex, endIndex := c.getStartAndEndRange(len(results))
 	return results[startIndex:endIndex], nil
 }
@@ -246,7 +433,7 @@ func (c *BaseCompoundCondition[T]) count(txn *badger.Txn, db *BormDb) (int, erro
 	if db.optConfig.QueryAnalyzer {
 		start := time.Now()
 		defer func() {
-			db.optConfig.Logger.Printf("[%v][%s][rows:%v]", time.Since(start), countAnalyzer(c), c.rows)
+			db.optConfig.Logger.Printf("[%v][%s][rows:%v]", time.Since(start), countAnalyzer(c, db.tableManager.GetTableName(c.row)), c.rows)
 		}()
 	}
 	ids, err := c.queryRowIds(txn, db)
@@ -277,15 +464,23 @@ func (c *BaseCompoundCondition[T]) leftPrefixIndexMatch(txn *badger.Txn, db *Bor
 		for _, key := range normalIdxMap.Keys() {
 			element := normalIdxMap.GetElement(key)
 
-			expectedVal, err := db.GetFieldValWithFieldIndex(row, element.Key)
+			//a multi-valued path index matches when any of the values matches
+			expectedVals, err := db.getFieldValsWithFieldIndex(row, element.Key)
 			if err != nil {
 				return err
 			}
-			cmp, err := c.compare(element.Value, expectedVal)
-			if err != nil {
-				return err
+			matched := false
+			for _, expectedVal := range expectedVals {
+				cmp, err := c.compare(element.Value, expectedVal)
+				if err != nil {
+					return err
+				}
+				if cmp == 0 {
+					matched = true
+					break
+				}
 			}
-			if cmp != 0 {
+			if !matched {
 				b = false
 				return nil
 			}
@@ -564,6 +759,27 @@ func (c *BaseCompoundCondition[T]) compare(value, other interface{}) (int, error
 			return -1, nil
 		}
 		return 1, nil
+	case bool:
+		tother, ok := other.(bool)
+		if !ok {
+			return 0, ErrTypeNotBeSort
+		}
+
+		if value.(bool) == tother {
+			return 0, nil
+		}
+
+		if !value.(bool) {
+			return -1, nil
+		}
+		return 1, nil
+	case []byte:
+		tother, ok := other.([]byte)
+		if !ok {
+			return 0, ErrTypeNotBeSort
+		}
+
+		return bytes.Compare(value.([]byte), tother), nil
 	default:
 		valS := fmt.Sprintf("%s", value)
 		otherS := fmt.Sprintf("%s", other)
@@ -583,13 +799,13 @@ type AndCompoundCondition[T IRow] struct {
 	*BaseCompoundCondition[T]
 }
 
-//OrCompoundCondition
-//TODO waiting to implemented
+// OrCompoundCondition
+// TODO waiting to implemented
 type OrCompoundCondition[T IRow] struct {
 	*BaseCompoundCondition[T]
 }
 
-//Eq like where user_id=568;
+// Eq like where user_id=568;
 func (condition *AndCompoundCondition[T]) Eq(fieldName string, value any) ICompoundConditions[T] {
 	_, ok := condition.fieldValueMap.Get(fieldName)
 	if ok {
@@ -600,7 +816,7 @@ func (condition *AndCompoundCondition[T]) Eq(fieldName string, value any) ICompo
 	return condition
 }
 
-//In like where (user_id,type) in ((568,6),(569,6),(600,8));
+// In like where (user_id,type) in ((568,6),(569,6),(600,8));
 func (condition *AndCompoundCondition[T]) In(fieldNames []string, values [][]any) ICompoundConditions[T] {
 	condition.inFilterConditions = append(condition.inFilterConditions, inFilterCondition{
 		fieldNames: fieldNames,
@@ -609,9 +825,52 @@ func (condition *AndCompoundCondition[T]) In(fieldNames []string, values [][]any
 	return condition
 }
 
+// StartsWith like where phone like '+86%', served by a unique or normal string index
+func (condition *AndCompoundCondition[T]) StartsWith(fieldName string, prefix string) ICompoundConditions[T] {
+	condition.prefixConditions = append(condition.prefixConditions, prefixCondition{
+		fieldName: fieldName,
+		prefix:    prefix,
+	})
+	return condition
+}
+
+// Like like where order_id like 'abc%', only a trailing '%' is supported,
+// a pattern without wildcards is Eq
+func (condition *AndCompoundCondition[T]) Like(fieldName string, pattern string) ICompoundConditions[T] {
+	prefix := strings.TrimSuffix(pattern, "%")
+	if strings.ContainsAny(prefix, "%_") {
+		condition.validated = false
+		return condition
+	}
+	if prefix == pattern {
+		return condition.Eq(fieldName, pattern)
+	}
+	return condition.StartsWith(fieldName, prefix)
+}
+
+// Match like where match(address) against('hong kong OR kowloon'), served by the fulltext index,
+// rows containing all words of any group separated by OR match
+func (condition *AndCompoundCondition[T]) Match(fieldName string, query string) ICompoundConditions[T] {
+	condition.matchConditions = append(condition.matchConditions, matchCondition{
+		fieldName: fieldName,
+		query:     query,
+	})
+	return condition
+}
+
 func (condition *AndCompoundCondition[T]) SortBy(reversed bool, sortKey ...string) ICompoundConditions[T] {
 	condition.reverse = reversed
 	condition.sortKey = sortKey
+	condition.relevance = false
+	return condition
+}
+
+// SortByRelevance
+// order by the tf-idf relevance of the match conditions, most relevant first
+func (condition *AndCompoundCondition[T]) SortByRelevance() ICompoundConditions[T] {
+	condition.sortKey = nil
+	condition.reverse = false
+	condition.relevance = true
 	return condition
 }
 
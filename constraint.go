@@ -0,0 +1,214 @@
+package borm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+//ConstraintError
+//a row breaks the rule of a field, errors.Is matches ErrConstraintViolation
+type ConstraintError struct {
+	Table string
+	Field string
+	Rule  string
+	Value any
+}
+
+func (e *ConstraintError) Error() string {
+	return fmt.Sprintf("%v: %s.%s %s, got %v", ErrConstraintViolation, e.Table, e.Field, e.Rule, e.Value)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return ErrConstraintViolation
+}
+
+type checkDecl struct {
+	fieldName string
+	rules     string
+}
+
+//WithCheck
+//add the rules of a `check` tag to a field, e.g. the fields of generated messages
+func WithCheck(fieldName, rules string) TableOption {
+	return func(o *tableOptions) {
+		o.checks = append(o.checks, checkDecl{fieldName: fieldName, rules: rules})
+	}
+}
+
+//constraintRule
+//a single rule of a field and whether a value passes it
+type constraintRule struct {
+	rule  string
+	check func(v reflect.Value) bool
+}
+
+type fieldConstraint struct {
+	fieldName string
+	index     []int
+	rules     []constraintRule
+}
+
+//parseCheckRules
+//rules are separated by ',', `notempty` needs a non empty string, bytes, slice or map,
+//`min=N` and `max=N` bound a number or the length of a string, slice or map, `in=a|b`
+//takes one of the values, an enum by number or name, and `regex=` matches the whole string
+//against the rest of the tag, so it must come last
+func parseCheckRules(field reflect.StructField, rules string) ([]constraintRule, error) {
+	parsed := []constraintRule{}
+	for rules != "" {
+		rule := rules
+		if !strings.HasPrefix(rule, "regex=") {
+			if i := strings.IndexByte(rules, ','); i >= 0 {
+				rule = rules[:i]
+				rules = rules[i+1:]
+			} else {
+				rules = ""
+			}
+		} else {
+			rules = ""
+		}
+		rule = strings.TrimSpace(rule)
+		name, arg, _ := strings.Cut(rule, "=")
+		var check func(v reflect.Value) bool
+		var err error
+		switch name {
+		case "notempty":
+			check, err = notEmptyCheck(field.Type)
+		case "min", "max":
+			check, err = boundCheck(field.Type, name == "min", arg)
+		case "in":
+			check = inCheck(strings.Split(arg, "|"))
+		case "regex":
+			check, err = regexCheck(field.Type, arg)
+		default:
+			err = ErrConstraintIllegal
+		}
+		if err != nil {
+			return nil, errors.Wrapf(ErrConstraintIllegal, "%s %s", field.Name, rule)
+		}
+		parsed = append(parsed, constraintRule{rule: rule, check: check})
+	}
+	return parsed, nil
+}
+
+func notEmptyCheck(t reflect.Type) (func(v reflect.Value) bool, error) {
+	switch t.Kind() {
+	case reflect.String, reflect.Slice, reflect.Map:
+		return func(v reflect.Value) bool { return v.Len() > 0 }, nil
+	}
+	return nil, ErrConstraintIllegal
+}
+
+func boundCheck(t reflect.Type, min bool, arg string) (func(v reflect.Value) bool, error) {
+	bound, err := strconv.ParseFloat(arg, 64)
+	if err != nil {
+		return nil, err
+	}
+	within := func(x float64) bool {
+		if min {
+			return x >= bound
+		}
+		return x <= bound
+	}
+	switch kind := t.Kind(); {
+	case kind == reflect.String || kind == reflect.Slice || kind == reflect.Map:
+		return func(v reflect.Value) bool { return within(float64(v.Len())) }, nil
+	case isIntegerKind(kind) && kind >= reflect.Uint:
+		return func(v reflect.Value) bool { return within(float64(v.Uint())) }, nil
+	case isIntegerKind(kind):
+		return func(v reflect.Value) bool { return within(float64(v.Int())) }, nil
+	case kind == reflect.Float32 || kind == reflect.Float64:
+		return func(v reflect.Value) bool { return within(v.Float()) }, nil
+	}
+	return nil, ErrConstraintIllegal
+}
+
+func inCheck(values []string) func(v reflect.Value) bool {
+	return func(v reflect.Value) bool {
+		s := fmt.Sprint(v.Interface())
+		num := ""
+		if isIntegerKind(v.Kind()) && v.Kind() < reflect.Uint {
+			num = strconv.FormatInt(v.Int(), 10)
+		}
+		for _, val := range values {
+			if val == s || val == num {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func regexCheck(t reflect.Type, arg string) (func(v reflect.Value) bool, error) {
+	if t.Kind() != reflect.String {
+		return nil, ErrConstraintIllegal
+	}
+	//the pattern matches the whole value like the other rules
+	re, err := regexp.Compile("^(?:" + arg + ")$")
+	if err != nil {
+		return nil, err
+	}
+	return func(v reflect.Value) bool { return re.MatchString(v.String()) }, nil
+}
+
+//newFieldConstraints
+//the rules of the `check` tags of the row struct and of the WithCheck options
+func newFieldConstraints(rowType reflect.Type, checks []checkDecl) ([]*fieldConstraint, error) {
+	structType := rowStructType(rowType)
+	decls := []checkDecl{}
+	for i := 0; i < structType.NumField(); i++ {
+		if rules := structType.Field(i).Tag.Get("check"); rules != "" && rules != "-" {
+			decls = append(decls, checkDecl{fieldName: structType.Field(i).Name, rules: rules})
+		}
+	}
+	constraints := []*fieldConstraint{}
+	for _, decl := range append(decls, checks...) {
+		field, ok := structType.FieldByName(decl.fieldName)
+		if !ok || !field.IsExported() {
+			return nil, errors.Wrapf(ErrFieldNotFound, "check of %s", decl.fieldName)
+		}
+		rules, err := parseCheckRules(field, decl.rules)
+		if err != nil {
+			return nil, err
+		}
+		constraints = append(constraints, &fieldConstraint{fieldName: field.Name, index: field.Index, rules: rules})
+	}
+	return constraints, nil
+}
+
+//checkConstraints
+//the first rule the row breaks as a *ConstraintError
+func (t *TableManager) checkConstraints(tableId uint32, tableName string, row IRow) error {
+	constraints := t.GetConstraints(tableId)
+	if len(constraints) == 0 {
+		return nil
+	}
+	rowValue := rowStruct(row)
+	for _, constraint := range constraints {
+		fieldValue := rowValue.FieldByIndex(constraint.index)
+		for _, rule := range constraint.rules {
+			if !rule.check(fieldValue) {
+				return &ConstraintError{
+					Table: tableName,
+					Field: constraint.fieldName,
+					Rule:  rule.rule,
+					Value: fieldValue.Interface(),
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (t *TableManager) GetConstraints(tableId uint32) []*fieldConstraint {
+	v, ok := t.constraints.Load(tableId)
+	if !ok {
+		return nil
+	}
+	return v.([]*fieldConstraint)
+}
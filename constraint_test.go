@@ -0,0 +1,102 @@
+package borm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/longbridgeapp/borm/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/stretchr/testify/require"
+)
+
+type checkedOrder struct {
+	Id       uint64
+	Symbol   string  `idx:"normal" check:"notempty,regex=^[0-9A-Z]+\\.(HK|US)$"`
+	Quantity int64   `check:"min=1,max=1000000"`
+	Price    float64 `check:"min=0"`
+	Side     pb.Gender
+	Remark   string `check:"max=8"`
+}
+
+func TestConstraint(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&StructRow[checkedOrder]{}, WithTableName("CheckedOrder"), WithCheck("Side", "between=0|1"))
+		require.ErrorIs(t, err, ErrConstraintIllegal)
+		err = db.CreateTable(&StructRow[checkedOrder]{}, WithTableName("CheckedOrder"), WithCheck("Price", "notempty"))
+		require.ErrorIs(t, err, ErrConstraintIllegal)
+		err = db.CreateTable(&StructRow[checkedOrder]{}, WithTableName("CheckedOrder"), WithCheck("Size", "min=1"))
+		require.ErrorIs(t, err, ErrFieldNotFound)
+		err = db.CreateTable(&StructRow[checkedOrder]{}, WithTableName("CheckedOrder"), WithCheck("Side", "in=men|1"), WithCheck("Remark", "regex=[0-9a-z]*"))
+		require.NoError(t, err)
+
+		err = db.Insert(&StructRow[checkedOrder]{Row: checkedOrder{Symbol: "700.HK", Quantity: 100, Price: 351.2}})
+		require.NoError(t, err)
+		err = db.Insert(&StructRow[checkedOrder]{Row: checkedOrder{Symbol: "AAPL.US", Quantity: 1, Side: pb.Gender_women, Remark: "12345678"}})
+		require.NoError(t, err)
+
+		cases := []struct {
+			row   checkedOrder
+			field string
+			rule  string
+		}{
+			{checkedOrder{Quantity: 1}, "Symbol", "notempty"},
+			{checkedOrder{Symbol: "700.hk", Quantity: 1}, "Symbol", `regex=^[0-9A-Z]+\.(HK|US)$`},
+			{checkedOrder{Symbol: "700.HK"}, "Quantity", "min=1"},
+			{checkedOrder{Symbol: "700.HK", Quantity: 1000001}, "Quantity", "max=1000000"},
+			{checkedOrder{Symbol: "700.HK", Quantity: 1, Price: -0.5}, "Price", "min=0"},
+			{checkedOrder{Symbol: "700.HK", Quantity: 1, Remark: "123456789"}, "Remark", "max=8"},
+			{checkedOrder{Symbol: "700.HK", Quantity: 1, Side: 2}, "Side", "in=men|1"},
+			{checkedOrder{Symbol: "700.HK", Quantity: 1, Remark: "ABCx"}, "Remark", "regex=[0-9a-z]*"},
+		}
+		for _, c := range cases {
+			err = db.Insert(&StructRow[checkedOrder]{Row: c.row})
+			require.ErrorIs(t, err, ErrConstraintViolation)
+			var constraintErr *ConstraintError
+			require.ErrorAs(t, err, &constraintErr)
+			require.Equal(t, "CheckedOrder", constraintErr.Table)
+			require.Equal(t, c.field, constraintErr.Field)
+			require.Equal(t, c.rule, constraintErr.Rule)
+		}
+		//rejected rows don't use up ids of the sequence
+		row := &StructRow[checkedOrder]{Row: checkedOrder{Symbol: "9988.HK", Quantity: 1}}
+		err = db.Insert(row)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), row.Row.Id)
+		err = db.Delete(row.Row.Id, row)
+		require.NoError(t, err)
+
+		err = db.Update(1, &StructRow[checkedOrder]{Row: checkedOrder{Symbol: "700.HK", Quantity: 0}})
+		require.ErrorIs(t, err, ErrConstraintViolation)
+		err = db.Update(1, &StructRow[checkedOrder]{Row: checkedOrder{Symbol: "700.HK", Quantity: 200}})
+		require.NoError(t, err)
+		count, err := db.Count(&StructRow[checkedOrder]{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), count)
+	})
+}
+
+type trimmedOrder struct {
+	Id     uint64
+	Symbol string `check:"notempty"`
+}
+
+func (o *trimmedOrder) BeforeInsert(tx *badger.Txn) error {
+	o.Symbol = strings.TrimSpace(o.Symbol)
+	return nil
+}
+
+func TestConstraintAfterHook(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&StructRow[trimmedOrder]{}, WithTableName("TrimmedOrder"))
+		require.NoError(t, err)
+		err = db.Insert(&StructRow[trimmedOrder]{Row: trimmedOrder{Symbol: " 700.HK "}})
+		require.NoError(t, err)
+		//the hook empties the symbol after the first check
+		err = db.Insert(&StructRow[trimmedOrder]{Row: trimmedOrder{Symbol: "  "}})
+		require.ErrorIs(t, err, ErrConstraintViolation)
+		count, err := db.Count(&StructRow[trimmedOrder]{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), count)
+	})
+}
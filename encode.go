@@ -2,6 +2,11 @@ package borm
 
 import (
 	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/longbridgeapp/borm/common"
 )
 
 const (
@@ -12,23 +17,56 @@ func encodeSeqKey(id uint32) []byte {
 	return []byte(fmt.Sprintf("t:seq:%v", id))
 }
 
+//encodeSchemaKey
+//rewritten whenever the indexes of the table change, see bumpSchema
+func encodeSchemaKey(id uint32) []byte {
+	return []byte(fmt.Sprintf("t:schema:%v", id))
+}
+
+//encodeReferenceKey
+//written by the writes of child rows referencing the parent row and read by the deletes
+//and key updates of the parent row, so the two conflict instead of leaving an orphan
+func encodeReferenceKey(parentTableId uint32, parentId uint64) []byte {
+	return []byte(fmt.Sprintf("k:%v:%v", parentTableId, parentId))
+}
+
+func encodeReferencePrefix(parentTableId uint32) []byte {
+	return []byte(fmt.Sprintf("k:%v:", parentTableId))
+}
+
 func encodePKey(id uint32, pk_no uint64) []byte {
 	return []byte(fmt.Sprintf("t:%v:%v", id, pk_no))
 }
 
+//prefixes end with segment, so table 1 never matches the keys of table 10
+
 func encodeTablePrefixKey(id uint32) []byte {
-	return []byte(fmt.Sprintf("t:%v", id))
+	return []byte(fmt.Sprintf("t:%v:", id))
 }
 
 func encodeUqIndexKeyPrefix(id, fieldIdx uint32) []byte {
-	return []byte(fmt.Sprintf("u:%v:%v", id, fieldIdx))
+	return []byte(fmt.Sprintf("u:%v:%v:", id, fieldIdx))
 }
 func encodeNormalIndexPrefix(id, fieldIdx uint32) []byte {
-	return []byte(fmt.Sprintf("i:%v:%v", id, fieldIdx))
+	return []byte(fmt.Sprintf("i:%v:%v:", id, fieldIdx))
+}
+
+func encodeFulltextIndexPrefix(id, fieldIdx uint32) []byte {
+	return []byte(fmt.Sprintf("f:%v:%v:", id, fieldIdx))
+}
+
+func encodeFulltextTermPrefix(id, fieldIdx uint32, term string) []byte {
+	return []byte(fmt.Sprintf("f:%v:%v:%v:", id, fieldIdx, term))
+}
+
+//encodeFulltextKey
+//terms never contain segment, the value is the term frequency
+func encodeFulltextKey(id, fieldIdx uint32, term string, pk_no uint64) []byte {
+	return []byte(fmt.Sprintf("f:%v:%v:%v:%v", id, fieldIdx, term, pk_no))
 }
 
 func encodeUnionIndexPrefix(id uint32) []byte {
-	return []byte(fmt.Sprintf("n:%v", id))
+	return []byte(fmt.Sprintf("n:%v:", id))
 }
 
 func encodeUqIndexKey(id uint32, fieldIdx uint32, val any) []byte {
@@ -39,10 +77,111 @@ func encodeNormalIndexKey(id uint32, fieldIdx uint32, val any, pk_no uint64) []b
 	return []byte(fmt.Sprintf("i:%v:%v:%v:%v", id, fieldIdx, val, pk_no))
 }
 
-func encodeUnionIndexKey(id uint32, indexContent string) []byte {
-	return []byte(fmt.Sprintf("n:%v:%v", id, indexContent))
+func encodeUnionIndexNamePrefix(id uint32, name string) []byte {
+	return []byte(fmt.Sprintf("n:%v:%v:", id, name))
+}
+
+//union index keys are the name prefix followed by the ordered encoding of the field
+//values in union order, multi unions append the 8 byte row id, the value is always the row id
+
+func encodeUnionIndexKey(id uint32, name string, indexContent []byte) []byte {
+	return append(encodeUnionIndexNamePrefix(id, name), indexContent...)
+}
+
+func encodeMultiUnionIndexKey(id uint32, name string, indexContent []byte, pk_no uint64) []byte {
+	return append(encodeUnionIndexKey(id, name, indexContent), common.EncodedFromUInt64(pk_no)...)
+}
+
+//encodeUnionIndexContent
+//ordered encoding of the leading vals of the union fields, a leading subset of
+//the values encodes to a byte prefix of the full content
+func encodeUnionIndexContent(tags []*tag, vals []any) ([]byte, error) {
+	content := []byte{}
+	for i, val := range vals {
+		val, err := tags[i].normalize(val)
+		if err != nil {
+			return nil, err
+		}
+		content, err = encodeOrderedValue(content, tags[i].fieldType, val)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return content, nil
+}
+
+//encodeOrderedValue
+//append val converted to fieldType, the byte order of the encodings is the order of the values
+//and no encoding is a prefix of another one of the same type
+func encodeOrderedValue(buf []byte, fieldType FieldType, val any) ([]byte, error) {
+	val, err := normalizeIndexValue(fieldType, val)
+	if err != nil {
+		return nil, err
+	}
+	switch v := val.(type) {
+	case string:
+		return appendOrderedBytes(buf, []byte(v)), nil
+	case []byte:
+		return appendOrderedBytes(buf, v), nil
+	case bool:
+		if v {
+			return append(buf, 1), nil
+		}
+		return append(buf, 0), nil
+	case time.Time:
+		//seconds then nanoseconds, so times before 1678 and after 2262 keep their order
+		buf = append(buf, common.EncodedFromUInt64(uint64(v.Unix())^(1<<63))...)
+		return append(buf, common.EncodedFromUInt64(uint64(v.Nanosecond()))[4:]...), nil
+	case float32:
+		return appendOrderedFloat(buf, float64(v)), nil
+	case float64:
+		return appendOrderedFloat(buf, v), nil
+	case complex64:
+		return appendOrderedFloat(appendOrderedFloat(buf, float64(real(v))), float64(imag(v))), nil
+	case complex128:
+		return appendOrderedFloat(appendOrderedFloat(buf, real(v)), imag(v)), nil
+	}
+	rv := reflect.ValueOf(val)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return append(buf, common.EncodedFromUInt64(uint64(rv.Int())^(1<<63))...), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return append(buf, common.EncodedFromUInt64(rv.Uint())...), nil
+	}
+	return nil, ErrIdxNotSupport
+}
+
+//appendOrderedBytes
+//0x00 is escaped as 0x00 0xff and the bytes are terminated by 0x00 0x01
+func appendOrderedBytes(buf []byte, bs []byte) []byte {
+	for _, b := range bs {
+		buf = append(buf, b)
+		if b == 0x00 {
+			buf = append(buf, 0xff)
+		}
+	}
+	return append(buf, 0x00, 0x01)
+}
+
+func appendOrderedFloat(buf []byte, f float64) []byte {
+	bits := math.Float64bits(f)
+	if bits&(1<<63) != 0 {
+		bits = ^bits
+	} else {
+		bits |= 1 << 63
+	}
+	return append(buf, common.EncodedFromUInt64(bits)...)
+}
+
+func isIntegerKind(kind reflect.Kind) bool {
+	switch kind {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return true
+	}
+	return false
 }
 
 func encodeNormalIndexKeyPrefix(id uint32, fieldIdx uint32, val any) []byte {
-	return []byte(fmt.Sprintf("i:%v:%v:%v", id, fieldIdx, val))
+	return []byte(fmt.Sprintf("i:%v:%v:%v:", id, fieldIdx, val))
 }
@@ -36,12 +36,29 @@ var (
 )
 
 var (
-	ErrTableRepeat       = errors.New("Table already exists")
-	ErrTableNotFound     = errors.New("Table not found")
-	ErrIdxNotSupport     = errors.New("Index type not support")
-	ErrIdxUniqueConflict = errors.New("Unique index conflict")
-	ErrBatchInsertError  = errors.New("Number of inserts must be greater than 0")
-	ErrRowIdIllegal      = errors.New("The row id must be set")
-	ErrQueryInvalid      = errors.New("The query is invalid")
-	ErrTypeNotBeSort     = errors.New("The sort key type error")
+	ErrTableRepeat         = errors.New("Table already exists")
+	ErrTableNotFound       = errors.New("Table not found")
+	ErrIdxNotSupport       = errors.New("Index type not support")
+	ErrIdxUniqueConflict   = errors.New("Unique index conflict")
+	ErrBatchInsertError    = errors.New("Number of inserts must be greater than 0")
+	ErrRowIdIllegal        = errors.New("The row id must be set")
+	ErrQueryInvalid        = errors.New("The query is invalid")
+	ErrTypeNotBeSort       = errors.New("The sort key type error")
+	ErrSnapshotNotFound    = errors.New("Snapshot not found or expired")
+	ErrIdxRepeat           = errors.New("Index already exists")
+	ErrFieldNotFound       = errors.New("Field not found")
+	ErrTableNameEmpty      = errors.New("The table name must be set")
+	ErrRowTypeIllegal      = errors.New("The row must be a pointer to a struct")
+	ErrCodecNotSupport     = errors.New("Type not support by the codec")
+	ErrRowCloneIllegal     = errors.New("Clone must return a new row of the same type")
+	ErrTableNameUnstable   = errors.New("GetTableName must return the same name for every row")
+	ErrRowCodecIllegal     = errors.New("A zero row must round trip through the codec")
+	ErrRowTypeMismatch     = errors.New("The row type is not the type registered for the table")
+	ErrRowIdRepeat         = errors.New("Row id already exists")
+	ErrIdGeneratorIllegal  = errors.New("The id generator is illegal")
+	ErrForeignKeyIllegal   = errors.New("The foreign key must reference a unique or unique union index")
+	ErrForeignKeyViolation = errors.New("Foreign key constraint violated")
+	ErrConstraintIllegal   = errors.New("The check rule is illegal")
+	ErrConstraintViolation = errors.New("Check constraint violated")
+	ErrOptionIllegal       = errors.New("The option is illegal")
 )
@@ -0,0 +1,346 @@
+package borm
+
+import (
+	"reflect"
+	"strings"
+
+	badger "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+)
+
+//ForeignKeyAction
+//what deleting a parent row does to the child rows referencing it
+type ForeignKeyAction string
+
+const (
+	//the delete fails while child rows reference the parent row
+	Restrict ForeignKeyAction = "RESTRICT"
+	//the child rows are deleted with the parent row
+	Cascade ForeignKeyAction = "CASCADE"
+	//the referencing fields of the child rows are set to their zero values
+	SetZero ForeignKeyAction = "SET ZERO"
+)
+
+type foreignKeyDecl struct {
+	name     string
+	parent   IRow
+	onDelete ForeignKeyAction
+	on       []string
+}
+
+//WithForeignKey
+//the fields of the table reference the unique or unique union index over the fields of the
+//parent table, on entries are field names of both tables or `ChildField=ParentField`, inserts
+//and updates need a parent row unless all the fields are zero, onDelete decides what deleting
+//a referenced parent row does, updating the referenced fields of a parent row always fails
+func WithForeignKey(name string, parent IRow, onDelete ForeignKeyAction, on ...string) TableOption {
+	return func(o *tableOptions) {
+		o.foreignKeys = append(o.foreignKeys, foreignKeyDecl{
+			name:     name,
+			parent:   parent,
+			onDelete: onDelete,
+			on:       on,
+		})
+	}
+}
+
+//foreignKey
+//a resolved foreign key, fields join the parent fields on the left to the child fields on the right,
+//values of both sides are normalized by the parent tags like checkForeignKeys does
+type foreignKey struct {
+	name          string
+	childTableId  uint32
+	parentTableId uint32
+	child         IRow
+	parent        IRow
+	fields        []joinField
+	//tags of the parent fields in the order of fields
+	parentTags []*tag
+	parentIdx  []uint32
+	onDelete   ForeignKeyAction
+}
+
+//newForeignKey
+//resolve decl for the child row type, the parent fields must be exactly a unique or unique union index
+func (t *TableManager) newForeignKey(childType reflect.Type, decl foreignKeyDecl) (*foreignKey, error) {
+	switch decl.onDelete {
+	case Restrict, Cascade, SetZero:
+	default:
+		return nil, ErrForeignKeyIllegal
+	}
+	if decl.name == "" || len(decl.on) == 0 {
+		return nil, ErrForeignKeyIllegal
+	}
+	parentTableId, err := t.GetTableId(t.GetTableName(decl.parent))
+	if err != nil {
+		return nil, err
+	}
+	on := make([]string, len(decl.on))
+	for i, name := range decl.on {
+		//stored as parent=child, the parent probes the child rows like a join
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			name = name[i+1:] + "=" + name[:i]
+		}
+		on[i] = name
+	}
+	fields, err := parseJoinOn(childType, on)
+	if err != nil {
+		return nil, err
+	}
+	fk := &foreignKey{
+		name:          decl.name,
+		parentTableId: parentTableId,
+		child:         reflect.New(childType.Elem()).Interface().(IRow),
+		parent:        cloneRow(decl.parent),
+		fields:        fields,
+		onDelete:      decl.onDelete,
+	}
+	tags := t.GetIndexTags(parentTableId)
+	for i, field := range fields {
+		found := false
+		for idx, tag := range tags {
+			if tag.fieldName == field.left && tag.path == nil && tag.predicate == nil {
+				fk.parentTags = append(fk.parentTags, tag)
+				fk.parentIdx = append(fk.parentIdx, idx)
+				fields[i].rightTag = tag
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Wrapf(ErrForeignKeyIllegal, "%s is not indexed", field.left)
+		}
+	}
+	if len(fields) == 1 {
+		if !fk.parentTags[0].CheckIsUnique() {
+			return nil, errors.Wrapf(ErrForeignKeyIllegal, "%s is not a unique index", fields[0].left)
+		}
+		return fk, nil
+	}
+	if _, err := t.GetUnionIndex(parentTableId, fk.parentIdx, true); err != nil {
+		return nil, errors.Wrapf(ErrForeignKeyIllegal, "no unique union index over %v", decl.on)
+	}
+	return fk, nil
+}
+
+func (t *TableManager) addForeignKeys(childTableId uint32, fks []*foreignKey) {
+	t.foreignKeyLock.Lock()
+	defer t.foreignKeyLock.Unlock()
+	for _, fk := range fks {
+		fk.childTableId = childTableId
+		refs := t.GetReferences(fk.parentTableId)
+		t.references.Store(fk.parentTableId, append(refs[:len(refs):len(refs)], fk))
+	}
+	t.foreignKeys.Store(childTableId, fks)
+}
+
+//removeForeignKeys
+//forget the foreign keys of a dropped table, ErrForeignKeyViolation if other tables reference it
+func (t *TableManager) removeForeignKeys(tableId uint32) error {
+	t.foreignKeyLock.Lock()
+	defer t.foreignKeyLock.Unlock()
+	for _, fk := range t.GetReferences(tableId) {
+		if fk.childTableId != tableId {
+			return errors.Wrapf(ErrForeignKeyViolation, "referenced by foreign key %s", fk.name)
+		}
+	}
+	for _, fk := range t.GetForeignKeys(tableId) {
+		refs := []*foreignKey{}
+		for _, ref := range t.GetReferences(fk.parentTableId) {
+			if ref != fk {
+				refs = append(refs, ref)
+			}
+		}
+		t.references.Store(fk.parentTableId, refs)
+	}
+	t.foreignKeys.Delete(tableId)
+	t.references.Delete(tableId)
+	return nil
+}
+
+//GetForeignKeys
+//the foreign keys declared by the table
+func (t *TableManager) GetForeignKeys(tableId uint32) []*foreignKey {
+	v, ok := t.foreignKeys.Load(tableId)
+	if !ok {
+		return nil
+	}
+	return v.([]*foreignKey)
+}
+
+//GetReferences
+//the foreign keys referencing the table
+func (t *TableManager) GetReferences(tableId uint32) []*foreignKey {
+	v, ok := t.references.Load(tableId)
+	if !ok {
+		return nil
+	}
+	return v.([]*foreignKey)
+}
+
+//checkForeignKeys
+//every foreign key of the child row with a non zero field has a parent row
+func (bormDb *BormDb) checkForeignKeys(txn *badger.Txn, tableId uint32, row IRow) error {
+	fks := bormDb.tableManager.GetForeignKeys(tableId)
+	if len(fks) == 0 {
+		return nil
+	}
+	rowValue := rowStruct(row)
+	for _, fk := range fks {
+		zero := true
+		idxConditionsMap := make(map[uint32]any, len(fk.fields))
+		for i, field := range fk.fields {
+			fieldValue := rowValue.FieldByIndex(field.rightIndex)
+			zero = zero && fieldValue.IsZero()
+			val, err := fk.parentTags[i].normalize(fieldValue.Interface())
+			if err != nil {
+				return err
+			}
+			idxConditionsMap[fk.parentIdx[i]] = val
+		}
+		if zero {
+			continue
+		}
+		var (
+			parentId uint64
+			err      error
+		)
+		if len(fk.fields) == 1 {
+			parentId, err = bormDb.TxQueryWithUniqueIndex(txn, fk.parent, fk.parentIdx[0], idxConditionsMap[fk.parentIdx[0]])
+		} else {
+			parentId, err = bormDb.TxQueryWithUnionIndex(txn, fk.parent, idxConditionsMap)
+		}
+		if err == ErrKeyNotFound {
+			return errors.Wrapf(ErrForeignKeyViolation, "no parent row of foreign key %s", fk.name)
+		}
+		if err != nil {
+			return err
+		}
+		err = txn.Set(encodeReferenceKey(fk.parentTableId, parentId), []byte{})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//readReferenceKey
+//track the reference key of the parent row, a child row referencing it committed after the
+//txn started fails the txn with ErrConflict, as the scan of the child rows can't see it
+func readReferenceKey(txn *badger.Txn, tableId uint32, parentId uint64) error {
+	_, err := txn.Get(encodeReferenceKey(tableId, parentId))
+	if err == badger.ErrKeyNotFound {
+		return nil
+	}
+	return err
+}
+
+//referencingRows
+//the child rows of fk referencing the parent row, none when the parent fields are all zero
+func (bormDb *BormDb) referencingRows(txn *badger.Txn, fk *foreignKey, parentRow IRow) ([]IRow, error) {
+	zero := true
+	vals := make([]any, len(fk.fields))
+	for i := range fk.fields {
+		val, err := firstIndexValue(fk.parentTags[i], parentRow)
+		if err != nil {
+			return nil, err
+		}
+		zero = zero && reflect.ValueOf(val).IsZero()
+		vals[i] = val
+	}
+	if zero {
+		return nil, nil
+	}
+	if bormDb.childIndexed(fk) {
+		return probeJoinTable(txn, bormDb, fk.child, fk.fields, vals)
+	}
+	return scanJoinTable(txn, bormDb, fk.child, fk.fields, vals)
+}
+
+//childIndexed
+//whether the child fields have indexes to probe that fold values like the parent tags, a
+//case-sensitive child index misses the child rows differing from the parent value in case
+func (bormDb *BormDb) childIndexed(fk *foreignKey) bool {
+	if !bormDb.joinIndexed(fk.childTableId, fk.fields) {
+		return false
+	}
+	for i, field := range fk.fields {
+		tag, err := bormDb.tableManager.GetIndexTag(fk.childTableId, field.right)
+		if err != nil || tag.caseInsensitive != fk.parentTags[i].caseInsensitive {
+			return false
+		}
+	}
+	return true
+}
+
+//deleteReferences
+//apply the delete actions of the foreign keys referencing the parent row
+func (bormDb *BormDb) deleteReferences(txn *badger.Txn, tableId uint32, parentRow IRow) error {
+	fks := bormDb.tableManager.GetReferences(tableId)
+	if len(fks) == 0 {
+		return nil
+	}
+	err := readReferenceKey(txn, tableId, rowId(parentRow))
+	if err != nil {
+		return err
+	}
+	for _, fk := range fks {
+		rows, err := bormDb.referencingRows(txn, fk, parentRow)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			switch fk.onDelete {
+			case Restrict:
+				return errors.Wrapf(ErrForeignKeyViolation, "row %v references the parent row by foreign key %s", rowId(row), fk.name)
+			case Cascade:
+				err = bormDb.TxDelete(txn, rowId(row), row)
+			case SetZero:
+				rowValue := rowStruct(row)
+				for _, field := range fk.fields {
+					fieldValue := rowValue.FieldByIndex(field.rightIndex)
+					fieldValue.Set(reflect.Zero(fieldValue.Type()))
+				}
+				err = bormDb.TxUpdate(txn, rowId(row), row)
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return txn.Delete(encodeReferenceKey(tableId, rowId(parentRow)))
+}
+
+//checkReferencedUpdate
+//the referenced fields of a parent row can't change while child rows reference it
+func (bormDb *BormDb) checkReferencedUpdate(txn *badger.Txn, tableId uint32, oldRow, newRow IRow) error {
+	for _, fk := range bormDb.tableManager.GetReferences(tableId) {
+		changed := false
+		for _, tag := range fk.parentTags {
+			oldVal, err := firstIndexValue(tag, oldRow)
+			if err != nil {
+				return err
+			}
+			newVal, err := firstIndexValue(tag, newRow)
+			if err != nil {
+				return err
+			}
+			changed = changed || !indexValueEqual(oldVal, newVal)
+		}
+		if !changed {
+			continue
+		}
+		err := readReferenceKey(txn, tableId, rowId(oldRow))
+		if err != nil {
+			return err
+		}
+		rows, err := bormDb.referencingRows(txn, fk, oldRow)
+		if err != nil {
+			return err
+		}
+		if len(rows) > 0 {
+			return errors.Wrapf(ErrForeignKeyViolation, "row %v references the parent row by foreign key %s", rowId(rows[0]), fk.name)
+		}
+	}
+	return nil
+}
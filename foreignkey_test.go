@@ -0,0 +1,228 @@
+package borm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/longbridgeapp/borm/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForeignKey(t *testing.T) {
+	prepare := func(t *testing.T, db *BormDb, onDelete ForeignKeyAction) {
+		err := db.CreateTable(&pb.AccountInfo{})
+		require.NoError(t, err)
+		err = db.CreateTable(&pb.Order{}, WithForeignKey("fk_account", &pb.AccountInfo{}, onDelete, "AccountChannel", "Aaid"))
+		require.NoError(t, err)
+		for i := 1; i <= 2; i++ {
+			err = db.Insert(&pb.AccountInfo{AccountChannel: "lb", Aaid: uint64(i)})
+			require.NoError(t, err)
+		}
+		for i := 0; i < 4; i++ {
+			err = db.Insert(&pb.Order{AccountChannel: "lb", Aaid: uint64(i%2 + 1), OrderId: fmt.Sprint(i)})
+			require.NoError(t, err)
+		}
+	}
+	t.Run("declare", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			err := db.CreateTable(&pb.Order{}, WithForeignKey("fk_account", &pb.AccountInfo{}, Cascade, "AccountChannel", "Aaid"))
+			require.ErrorIs(t, err, ErrTableNotFound)
+			err = db.CreateTable(&pb.Person{})
+			require.NoError(t, err)
+			err = db.CreateTable(&pb.Order{}, WithForeignKey("fk_person", &pb.Person{}, Cascade, "OrgId=Name"))
+			require.ErrorIs(t, err, ErrForeignKeyIllegal)
+			err = db.CreateTable(&pb.Order{}, WithForeignKey("fk_person", &pb.Person{}, "NO ACTION", "OrgId=Phone"))
+			require.ErrorIs(t, err, ErrForeignKeyIllegal)
+			err = db.CreateTable(&pb.Order{}, WithForeignKey("fk_person", &pb.Person{}, Restrict, "OrgId=Phone"))
+			require.NoError(t, err)
+
+			err = db.Insert(&pb.Order{OrderId: "1", OrgId: "+861"})
+			require.ErrorIs(t, err, ErrForeignKeyViolation)
+			//zero fields reference nothing
+			err = db.Insert(&pb.Order{OrderId: "1"})
+			require.NoError(t, err)
+			person := &pb.Person{Name: "jacky", Phone: "+861"}
+			err = db.Insert(person)
+			require.NoError(t, err)
+			err = db.Insert(&pb.Order{OrderId: "2", OrgId: "+861"})
+			require.NoError(t, err)
+			err = db.Update(person.Id, &pb.Person{Name: "rose", Phone: "+861"})
+			require.NoError(t, err)
+			err = db.Update(person.Id, &pb.Person{Name: "rose", Phone: "+862"})
+			require.ErrorIs(t, err, ErrForeignKeyViolation)
+			err = db.DropTable(&pb.Person{})
+			require.ErrorIs(t, err, ErrForeignKeyViolation)
+			err = db.DropTable(&pb.Order{})
+			require.NoError(t, err)
+			err = db.DropTable(&pb.Person{})
+			require.NoError(t, err)
+		})
+	})
+	t.Run("restrict", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			prepare(t, db, Restrict)
+			err := db.Insert(&pb.Order{AccountChannel: "lb", Aaid: 3, OrderId: "4"})
+			require.ErrorIs(t, err, ErrForeignKeyViolation)
+			err = db.Update(1, &pb.Order{AccountChannel: "lb", Aaid: 3, OrderId: "0"})
+			require.ErrorIs(t, err, ErrForeignKeyViolation)
+			err = db.Delete(1, &pb.AccountInfo{})
+			require.ErrorIs(t, err, ErrForeignKeyViolation)
+			err = db.Delete(1, &pb.Order{})
+			require.NoError(t, err)
+			err = db.Delete(3, &pb.Order{})
+			require.NoError(t, err)
+			err = db.Delete(1, &pb.AccountInfo{})
+			require.NoError(t, err)
+		})
+	})
+	t.Run("cascade", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			prepare(t, db, Cascade)
+			err := db.Delete(2, &pb.AccountInfo{})
+			require.NoError(t, err)
+			orders, err := Find(db, WithAnd(&pb.Order{}).Eq("AccountChannel", "lb"))
+			require.NoError(t, err)
+			require.Len(t, orders, 2)
+			for _, order := range orders {
+				require.Equal(t, uint64(1), order.Aaid)
+			}
+		})
+	})
+	t.Run("set zero", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			prepare(t, db, SetZero)
+			err := db.Delete(2, &pb.AccountInfo{})
+			require.NoError(t, err)
+			count, err := db.Count(&pb.Order{})
+			require.NoError(t, err)
+			require.Equal(t, uint64(4), count)
+			orders, err := Find(db, WithAnd(&pb.Order{}).Eq("Aaid", uint64(0)))
+			require.NoError(t, err)
+			require.Len(t, orders, 2)
+			for _, order := range orders {
+				require.Equal(t, "", order.AccountChannel)
+				require.Contains(t, []string{"1", "3"}, order.OrderId)
+			}
+		})
+	})
+	t.Run("truncate", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			prepare(t, db, Cascade)
+			err := db.Truncate(&pb.AccountInfo{})
+			require.ErrorIs(t, err, ErrForeignKeyViolation)
+			count, err := db.Count(&pb.AccountInfo{})
+			require.NoError(t, err)
+			require.Equal(t, uint64(2), count)
+			err = db.Truncate(&pb.Order{})
+			require.NoError(t, err)
+			err = db.Truncate(&pb.AccountInfo{})
+			require.NoError(t, err)
+			count, err = db.Count(&pb.AccountInfo{})
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), count)
+		})
+	})
+	t.Run("concurrent child insert", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			prepare(t, db, Restrict)
+			account := &pb.AccountInfo{AccountChannel: "lb", Aaid: 3}
+			err := db.Insert(account)
+			require.NoError(t, err)
+
+			//the delete sees no order of the account, the insert still sees the account
+			parentTxn := db.Begin(true)
+			defer db.Discard(parentTxn)
+			childTxn := db.Begin(true)
+			defer db.Discard(childTxn)
+			err = db.TxDelete(parentTxn, account.Id, &pb.AccountInfo{})
+			require.NoError(t, err)
+			err = db.TxInsert(childTxn, &pb.Order{AccountChannel: "lb", Aaid: 3, OrderId: "4"})
+			require.NoError(t, err)
+			require.NoError(t, db.Commit(childTxn))
+			require.ErrorIs(t, db.Commit(parentTxn), badger.ErrConflict)
+			err = db.Delete(account.Id, &pb.AccountInfo{})
+			require.ErrorIs(t, err, ErrForeignKeyViolation)
+
+			//a parent delete committed first fails the child insert
+			account = &pb.AccountInfo{AccountChannel: "lb", Aaid: 4}
+			err = db.Insert(account)
+			require.NoError(t, err)
+			parentTxn = db.Begin(true)
+			defer db.Discard(parentTxn)
+			childTxn = db.Begin(true)
+			defer db.Discard(childTxn)
+			err = db.TxDelete(parentTxn, account.Id, &pb.AccountInfo{})
+			require.NoError(t, err)
+			err = db.TxInsert(childTxn, &pb.Order{AccountChannel: "lb", Aaid: 4, OrderId: "5"})
+			require.NoError(t, err)
+			require.NoError(t, db.Commit(parentTxn))
+			require.ErrorIs(t, db.Commit(childTxn), badger.ErrConflict)
+			err = db.Insert(&pb.Order{AccountChannel: "lb", Aaid: 4, OrderId: "5"})
+			require.ErrorIs(t, err, ErrForeignKeyViolation)
+		})
+	})
+	t.Run("case-insensitive parent", func(t *testing.T) {
+		for _, onDelete := range []ForeignKeyAction{Restrict, Cascade, SetZero} {
+			runNewBorm(t, func(t *testing.T, db *BormDb) {
+				err := db.CreateTable(&ciAccount{})
+				require.NoError(t, err)
+				//the ci index of ciOrder is probed, csOrder is scanned as its index is case-sensitive
+				err = db.CreateTable(&StructRow[ciOrder]{}, WithForeignKey("fk_ci", &ciAccount{}, onDelete, "Email"))
+				require.NoError(t, err)
+				err = db.CreateTable(&StructRow[csOrder]{}, WithForeignKey("fk_cs", &ciAccount{}, onDelete, "Email"))
+				require.NoError(t, err)
+				parent := &ciAccount{Name: "jacky", Email: "abc"}
+				err = db.Insert(parent)
+				require.NoError(t, err)
+				ci := &StructRow[ciOrder]{Row: ciOrder{Email: "ABC"}}
+				err = db.Insert(ci)
+				require.NoError(t, err)
+				err = db.Insert(&StructRow[csOrder]{Row: csOrder{Email: "ABC"}})
+				require.NoError(t, err)
+				err = db.Insert(&StructRow[csOrder]{Row: csOrder{Email: "abd"}})
+				require.ErrorIs(t, err, ErrForeignKeyViolation)
+
+				err = db.Update(parent.Id, &ciAccount{Name: "jacky", Email: "xyz"})
+				require.ErrorIs(t, err, ErrForeignKeyViolation)
+				err = db.Update(parent.Id, &ciAccount{Name: "rose", Email: "Abc"})
+				require.NoError(t, err)
+				err = db.Delete(parent.Id, &ciAccount{})
+				switch onDelete {
+				case Restrict:
+					require.ErrorIs(t, err, ErrForeignKeyViolation)
+					err = db.Delete(ci.Row.Id, &StructRow[ciOrder]{})
+					require.NoError(t, err)
+					err = db.Delete(parent.Id, &ciAccount{})
+					require.ErrorIs(t, err, ErrForeignKeyViolation)
+				case Cascade:
+					require.NoError(t, err)
+					for _, row := range []IRow{&StructRow[ciOrder]{}, &StructRow[csOrder]{}} {
+						count, err := db.Count(row)
+						require.NoError(t, err)
+						require.Equal(t, uint64(0), count)
+					}
+				case SetZero:
+					require.NoError(t, err)
+					ciOrders, err := Find(db, WithAnd(&StructRow[ciOrder]{}).Eq("Email", ""))
+					require.NoError(t, err)
+					require.Len(t, ciOrders, 1)
+					csOrders, err := Find(db, WithAnd(&StructRow[csOrder]{}).Eq("Email", ""))
+					require.NoError(t, err)
+					require.Len(t, csOrders, 1)
+				}
+			})
+		}
+	})
+}
+
+type ciOrder struct {
+	Id    uint64
+	Email string `idx:"normal,ci"`
+}
+
+type csOrder struct {
+	Id    uint64
+	Email string `idx:"normal"`
+}
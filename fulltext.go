@@ -0,0 +1,193 @@
+package borm
+
+import (
+	"bytes"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/longbridgeapp/borm/common"
+
+	badger "github.com/dgraph-io/badger/v3"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	//keyword separating the term groups of a match query
+	matchOr = "OR"
+)
+
+//tokenize
+//the folded words of s, letters and digits are words and every Han character is a word
+func tokenize(s string) []string {
+	terms := []string{}
+	word := []rune{}
+	flush := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+	for _, r := range strings.ToLower(norm.NFKC.String(s)) {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flush()
+			terms = append(terms, string(r))
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			word = append(word, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return terms
+}
+
+//termFrequencies
+//the count of every term of s
+func termFrequencies(s string) map[string]uint64 {
+	frequencies := map[string]uint64{}
+	for _, term := range tokenize(s) {
+		frequencies[term]++
+	}
+	return frequencies
+}
+
+//parseMatchQuery
+//terms of a group are all required, groups separated by OR are alternatives,
+//e.g. "hong kong OR kowloon"
+func parseMatchQuery(query string) [][]string {
+	groups := [][]string{}
+	for _, group := range strings.Split(" "+query+" ", " "+matchOr+" ") {
+		terms := tokenize(group)
+		if len(terms) > 0 {
+			groups = append(groups, terms)
+		}
+	}
+	return groups
+}
+
+func (bormDb *BormDb) createFulltextIndex(tableId, fieldIdx uint32, val any, txn *badger.Txn, next uint64, expiresAt uint64) error {
+	text, ok := val.(string)
+	if !ok {
+		return ErrIdxNotSupport
+	}
+	for term, frequency := range termFrequencies(text) {
+		err := setEntry(txn, encodeFulltextKey(tableId, fieldIdx, term, next), common.EncodedFromUInt64(frequency), expiresAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bormDb *BormDb) deleteFulltextIndex(tableId, fieldIdx uint32, val any, txn *badger.Txn, pk uint64) error {
+	text, ok := val.(string)
+	if !ok {
+		return ErrIdxNotSupport
+	}
+	for term := range termFrequencies(text) {
+		if err := txn.Delete(encodeFulltextKey(tableId, fieldIdx, term, pk)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+//txQueryTerm
+//the frequency of the term in every row containing it
+func (bormDb *BormDb) txQueryTerm(txn *badger.Txn, tableId, fieldIdx uint32, term string) (map[uint64]uint64, error) {
+	it := txn.NewIterator(badger.DefaultIteratorOptions)
+	defer it.Close()
+	prefix := encodeFulltextTermPrefix(tableId, fieldIdx, term)
+	frequencies := map[uint64]uint64{}
+	for it.Seek(prefix); it.ValidForPrefix(prefix); it.Next() {
+		item := it.Item()
+		itemKey := item.Key()
+		id, err := strconv.ParseUint(string(itemKey[bytes.LastIndexByte(itemKey, ':')+1:]), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		err = item.Value(func(val []byte) error {
+			frequencies[id] = common.DecodedToUInt64(val)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return frequencies, nil
+}
+
+//TxQueryWithFulltextIndex
+//ids of the rows matching the query on the fulltext index idx,
+//a row matches when it contains all terms of any group
+func (bormDb *BormDb) TxQueryWithFulltextIndex(txn *badger.Txn, row IRow, idx uint32, query string) ([]uint64, error) {
+	scores, err := bormDb.txMatch(txn, row, idx, query, false)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]uint64, 0, len(scores))
+	for id := range scores {
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+//TxQueryWithFulltextIndexScores
+//the matching rows like TxQueryWithFulltextIndex with their tf-idf relevance,
+//the score of a row is the best score of its matching groups
+func (bormDb *BormDb) TxQueryWithFulltextIndexScores(txn *badger.Txn, row IRow, idx uint32, query string) (map[uint64]float64, error) {
+	return bormDb.txMatch(txn, row, idx, query, true)
+}
+
+func (bormDb *BormDb) txMatch(txn *badger.Txn, row IRow, idx uint32, query string, relevance bool) (map[uint64]float64, error) {
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return nil, err
+	}
+	tag, ok := bormDb.tableManager.GetIndexTags(tableId)[idx]
+	if !ok || !tag.CheckIsFulltext() {
+		return nil, ErrIdxNotSupport
+	}
+	//counting the rows scans the table, only needed for the idf
+	totalRows := uint64(0)
+	if relevance {
+		totalRows = bormDb.countWithPrefix(txn, encodeTablePrefixKey(tableId))
+	}
+	scores := map[uint64]float64{}
+	for _, group := range parseMatchQuery(query) {
+		groupScores := map[uint64]float64{}
+		for i, term := range group {
+			frequencies, err := bormDb.txQueryTerm(txn, tableId, idx, term)
+			if err != nil {
+				return nil, err
+			}
+			idf := 1.0
+			if relevance {
+				idf = math.Log(1 + float64(totalRows)/float64(len(frequencies)+1))
+			}
+			if i == 0 {
+				for id, frequency := range frequencies {
+					groupScores[id] = float64(frequency) * idf
+				}
+				continue
+			}
+			for id := range groupScores {
+				frequency, ok := frequencies[id]
+				if !ok {
+					delete(groupScores, id)
+					continue
+				}
+				groupScores[id] += float64(frequency) * idf
+			}
+		}
+		for id, score := range groupScores {
+			if score > scores[id] {
+				scores[id] = score
+			}
+		}
+	}
+	return scores, nil
+}
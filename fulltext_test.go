@@ -0,0 +1,115 @@
+package borm
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type shop struct {
+	Id      uint64
+	Name    string `idx:"normal"`
+	Address string `idx:"fulltext"`
+	Remark  string
+}
+
+func (s *shop) Marshal() ([]byte, error) {
+	return json.Marshal(s)
+}
+
+func (s *shop) Unmarshal(bs []byte) error {
+	return json.Unmarshal(bs, s)
+}
+
+func (*shop) GetTableName() string {
+	return "shop"
+}
+
+func (*shop) Clone() any {
+	return &shop{}
+}
+
+func TestFulltextIndex(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&shop{})
+		require.NoError(t, err)
+		addresses := []string{
+			"1 Queen's Road Central, Hong Kong",
+			"88 Nathan Road, Kowloon, Hong Kong",
+			"Hong Kong Hong Kong Disneyland",
+			"香港九龍彌敦道",
+			"Nathan Road",
+		}
+		for i, address := range addresses {
+			err = db.Insert(&shop{Name: fmt.Sprintf("shop%d", i), Address: address, Remark: address})
+			require.NoError(t, err)
+		}
+
+		count, err := Count(db, WithAnd(&shop{}).Match("Address", "hong kong"))
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		count, err = Count(db, WithAnd(&shop{}).Match("Address", "NATHAN road"))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		count, err = Count(db, WithAnd(&shop{}).Match("Address", "central OR kowloon"))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		count, err = Count(db, WithAnd(&shop{}).Match("Address", "nathan").Eq("Name", "shop4"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		count, err = Count(db, WithAnd(&shop{}).Match("Address", "九龍"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		count, err = Count(db, WithAnd(&shop{}).Match("Address", "tokyo"))
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+		_, err = Find(db, WithAnd(&shop{}).Match("Remark", "hong"))
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+
+		results, err := Find(db, WithAnd(&shop{}).Match("Address", "hong kong").SortByRelevance())
+		require.NoError(t, err)
+		require.Equal(t, 3, len(results))
+		require.Equal(t, "shop2", results[0].Name)
+
+		first, err := First(db, WithAnd(&shop{}).Eq("Name", "shop1"))
+		require.NoError(t, err)
+		err = db.Update(first.Id, &shop{Name: "shop1", Address: "Mong Kok"})
+		require.NoError(t, err)
+		count, err = Count(db, WithAnd(&shop{}).Match("Address", "kowloon"))
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+		count, err = Count(db, WithAnd(&shop{}).Match("Address", "mong kok"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		first, err = First(db, WithAnd(&shop{}).Eq("Name", "shop0"))
+		require.NoError(t, err)
+		err = db.Delete(first.Id, &shop{})
+		require.NoError(t, err)
+		count, err = Count(db, WithAnd(&shop{}).Match("Address", "central"))
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+
+		detail, err := db.Snoop(&shop{})
+		require.NoError(t, err)
+		//hong,kong,disneyland + 香,港,九,龍,彌,敦,道 + nathan,road + mong,kok
+		require.Equal(t, uint64(14), detail.FulltextIndex["Address"])
+
+		err = db.CreateIndex(&shop{}, "Remark", FULLTEXT)
+		require.NoError(t, err)
+		//the remark of shop1 was cleared by the update
+		count, err = Count(db, WithAnd(&shop{}).Match("Remark", "road"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		err = db.CreateIndex(&shop{}, "Id", FULLTEXT)
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+		err = db.DropIndex(&shop{}, "Remark")
+		require.NoError(t, err)
+		_, err = Find(db, WithAnd(&shop{}).Match("Remark", "road"))
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+		detail, err = db.Snoop(&shop{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(detail.FulltextIndex))
+	})
+}
@@ -0,0 +1,77 @@
+package borm
+
+import (
+	badger "github.com/dgraph-io/badger/v3"
+)
+
+//Row lifecycle hooks
+//an IRow, or the struct of a StructRow, may implement any of the interfaces below,
+//the hooks run inside the writing txn and a returned error aborts the write
+
+type BeforeInsertHook interface {
+	BeforeInsert(tx *badger.Txn) error
+}
+
+type AfterInsertHook interface {
+	AfterInsert(tx *badger.Txn) error
+}
+
+//BeforeUpdateHook is called on the new row before it is marshaled
+type BeforeUpdateHook interface {
+	BeforeUpdate(tx *badger.Txn) error
+}
+
+type AfterUpdateHook interface {
+	AfterUpdate(tx *badger.Txn) error
+}
+
+//BeforeDeleteHook is called on the stored row that is going to be deleted
+type BeforeDeleteHook interface {
+	BeforeDelete(tx *badger.Txn) error
+}
+
+type AfterDeleteHook interface {
+	AfterDelete(tx *badger.Txn) error
+}
+
+func callBeforeInsert(tx *badger.Txn, row IRow) error {
+	if hook, ok := rowTarget(row).(BeforeInsertHook); ok {
+		return hook.BeforeInsert(tx)
+	}
+	return nil
+}
+
+func callAfterInsert(tx *badger.Txn, row IRow) error {
+	if hook, ok := rowTarget(row).(AfterInsertHook); ok {
+		return hook.AfterInsert(tx)
+	}
+	return nil
+}
+
+func callBeforeUpdate(tx *badger.Txn, row IRow) error {
+	if hook, ok := rowTarget(row).(BeforeUpdateHook); ok {
+		return hook.BeforeUpdate(tx)
+	}
+	return nil
+}
+
+func callAfterUpdate(tx *badger.Txn, row IRow) error {
+	if hook, ok := rowTarget(row).(AfterUpdateHook); ok {
+		return hook.AfterUpdate(tx)
+	}
+	return nil
+}
+
+func callBeforeDelete(tx *badger.Txn, row IRow) error {
+	if hook, ok := rowTarget(row).(BeforeDeleteHook); ok {
+		return hook.BeforeDelete(tx)
+	}
+	return nil
+}
+
+func callAfterDelete(tx *badger.Txn, row IRow) error {
+	if hook, ok := rowTarget(row).(AfterDeleteHook); ok {
+		return hook.AfterDelete(tx)
+	}
+	return nil
+}
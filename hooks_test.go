@@ -0,0 +1,88 @@
+package borm
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/stretchr/testify/require"
+)
+
+var errEmptyName = errors.New("empty name")
+
+type hookedPerson struct {
+	Id       uint64
+	Name     string `idx:"normal"`
+	Currency string `idx:"normal"`
+	Version  uint32
+}
+
+func (p *hookedPerson) Marshal() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+func (p *hookedPerson) Unmarshal(bs []byte) error {
+	return json.Unmarshal(bs, p)
+}
+
+func (*hookedPerson) GetTableName() string {
+	return "hookedPerson"
+}
+
+func (*hookedPerson) Clone() any {
+	return &hookedPerson{}
+}
+
+func (p *hookedPerson) BeforeInsert(tx *badger.Txn) error {
+	if p.Name == "" {
+		return errEmptyName
+	}
+	p.Currency = strings.ToUpper(p.Currency)
+	return nil
+}
+
+func (p *hookedPerson) BeforeUpdate(tx *badger.Txn) error {
+	p.Currency = strings.ToUpper(p.Currency)
+	p.Version++
+	return nil
+}
+
+func (p *hookedPerson) BeforeDelete(tx *badger.Txn) error {
+	if p.Currency == "HKD" {
+		return errEmptyName
+	}
+	return nil
+}
+
+func TestRowHooks(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&hookedPerson{})
+		require.NoError(t, err)
+
+		err = db.Insert(&hookedPerson{Currency: "usd"})
+		require.ErrorIs(t, err, errEmptyName)
+		count, err := db.Count(&hookedPerson{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), count)
+
+		err = db.Insert(&hookedPerson{Name: "jacky", Currency: "usd"})
+		require.NoError(t, err)
+		person, err := First(db, WithAnd(&hookedPerson{}).Eq("Currency", "USD"))
+		require.NoError(t, err)
+		require.Equal(t, "jacky", person.Name)
+
+		err = db.Update(person.Id, &hookedPerson{Name: "jacky", Currency: "hkd"})
+		require.NoError(t, err)
+		person, err = First(db, WithAnd(&hookedPerson{}).Eq("Currency", "HKD"))
+		require.NoError(t, err)
+		require.Equal(t, uint32(1), person.Version)
+
+		err = db.Delete(person.Id, &hookedPerson{})
+		require.ErrorIs(t, err, errEmptyName)
+		count, err = db.Count(&hookedPerson{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), count)
+	})
+}
@@ -0,0 +1,84 @@
+package borm
+
+import (
+	"sync"
+	"time"
+)
+
+//IdGenerator
+//hands out the ids of inserted rows, set by WithIdGenerator, ids must be non zero
+//and unique within the table, the default is a sequence starting at 1
+type IdGenerator interface {
+	Next() (uint64, error)
+}
+
+const (
+	snowflakeNodeBits = 10
+	snowflakeStepBits = 12
+	snowflakeMaxNode  = 1<<snowflakeNodeBits - 1
+	snowflakeMaxStep  = 1<<snowflakeStepBits - 1
+)
+
+//snowflakeEpoch
+//2020-01-01 UTC, the 41 bits of milliseconds last until 2089
+var snowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+type snowflakeIdGenerator struct {
+	lock sync.Mutex
+	node uint64
+	last int64
+	step uint64
+}
+
+//NewSnowflakeIdGenerator
+//ids of 41 bits of milliseconds since 2020, 10 bits of node and 12 bits of sequence,
+//so processes with distinct nodes never hand out the same id
+func NewSnowflakeIdGenerator(node uint16) (IdGenerator, error) {
+	if node > snowflakeMaxNode {
+		return nil, ErrIdGeneratorIllegal
+	}
+	return &snowflakeIdGenerator{node: uint64(node)}, nil
+}
+
+func (g *snowflakeIdGenerator) Next() (uint64, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	now := time.Since(snowflakeEpoch).Milliseconds()
+	//the clock going backwards keeps the last millisecond
+	if now < g.last {
+		now = g.last
+	}
+	if now == g.last {
+		g.step = (g.step + 1) & snowflakeMaxStep
+		if g.step == 0 {
+			//the millisecond is used up, borrow the next one
+			now++
+		}
+	} else {
+		g.step = 0
+	}
+	g.last = now
+	return uint64(now)<<(snowflakeNodeBits+snowflakeStepBits) | g.node<<snowflakeStepBits | g.step, nil
+}
+
+type timeIdGenerator struct {
+	lock sync.Mutex
+	last uint64
+}
+
+//NewTimeIdGenerator
+//ids are the unix nanoseconds of the insert, bumped to stay strictly increasing
+func NewTimeIdGenerator() IdGenerator {
+	return &timeIdGenerator{}
+}
+
+func (g *timeIdGenerator) Next() (uint64, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	now := uint64(time.Now().UnixNano())
+	if now <= g.last {
+		now = g.last + 1
+	}
+	g.last = now
+	return now, nil
+}
@@ -0,0 +1,159 @@
+package borm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/longbridgeapp/borm/common"
+	"github.com/longbridgeapp/borm/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertWithId(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.InsertWithId(&pb.Person{Name: "jacky", Phone: "+861"})
+		require.ErrorIs(t, err, ErrRowIdIllegal)
+		err = db.InsertWithId(&pb.Person{Id: 100, Name: "jacky", Phone: "+861"})
+		require.NoError(t, err)
+		err = db.InsertWithId(&pb.Person{Id: 100, Name: "rose", Phone: "+862"})
+		require.ErrorIs(t, err, ErrRowIdRepeat)
+		person, err := First(db, WithAnd(&pb.Person{}).Eq("Phone", "+861"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(100), person.Id)
+
+		//ids of the sequence skip the caller ids
+		person = &pb.Person{Id: 100, Name: "rose", Phone: "+862"}
+		err = db.Insert(person)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), person.Id)
+		err = db.InsertWithId(&pb.Person{Id: 2, Name: "lily", Phone: "+863"})
+		require.NoError(t, err)
+		err = db.InsertWithId(&pb.Person{Id: 3, Name: "lily", Phone: "+864"})
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			person = &pb.Person{Name: "lucy", Phone: fmt.Sprint(i)}
+			err = db.Insert(person)
+			require.NoError(t, err)
+			require.Equal(t, uint64(4+i), person.Id)
+		}
+		count, err := db.Count(&pb.Person{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), count)
+	})
+}
+
+func TestIdGenerator(t *testing.T) {
+	_, err := NewSnowflakeIdGenerator(1024)
+	require.ErrorIs(t, err, ErrIdGeneratorIllegal)
+	snowflake, err := NewSnowflakeIdGenerator(3)
+	require.NoError(t, err)
+	for _, gen := range []IdGenerator{snowflake, NewTimeIdGenerator()} {
+		last := uint64(0)
+		for i := 0; i < 10000; i++ {
+			id, err := gen.Next()
+			require.NoError(t, err)
+			require.Greater(t, id, last)
+			last = id
+		}
+	}
+	id, err := snowflake.Next()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), (id>>12)&1023)
+
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Order{}, WithIdGenerator(snowflake))
+		require.NoError(t, err)
+		first := &pb.Order{Aaid: 1, OrderId: "a"}
+		err = db.Insert(first)
+		require.NoError(t, err)
+		second := &pb.Order{Aaid: 1, OrderId: "b"}
+		err = db.Insert(second)
+		require.NoError(t, err)
+		require.Greater(t, second.Id, first.Id)
+		order, err := First(db, WithAnd(&pb.Order{}).Eq("OrderId", "a").Eq("Aaid", uint64(1)).Eq("AccountChannel", ""))
+		require.NoError(t, err)
+		require.Equal(t, first.Id, order.Id)
+	})
+}
+
+func TestSequence(t *testing.T) {
+	t.Run("start and lease", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			gen := NewTimeIdGenerator()
+			err := db.CreateTable(&pb.Person{}, WithIdGenerator(gen), WithGapFreeIds())
+			require.ErrorIs(t, err, ErrIdGeneratorIllegal)
+			err = db.CreateTable(&pb.Person{}, WithSequence(1000, 10))
+			require.NoError(t, err)
+			for i := 0; i < 3; i++ {
+				person := &pb.Person{Name: "jacky", Phone: fmt.Sprint(i)}
+				err = db.Insert(person)
+				require.NoError(t, err)
+				require.Equal(t, uint64(1000+i), person.Id)
+			}
+			//the lease is returned up to the next unused id
+			err = db.tableManager.Close()
+			require.NoError(t, err)
+			err = db.View(func(txn *badger.Txn) error {
+				item, err := txn.Get(encodeSeqKey(0))
+				if err != nil {
+					return err
+				}
+				return item.Value(func(val []byte) error {
+					require.Equal(t, uint64(1003), common.DecodedToUInt64(val))
+					return nil
+				})
+			})
+			require.NoError(t, err)
+		})
+	})
+	t.Run("gap free", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			err := db.CreateTable(&pb.Person{}, WithGapFreeIds())
+			require.NoError(t, err)
+			txn := db.Begin(true)
+			err = db.TxInsert(txn, &pb.Person{Name: "jacky", Phone: "+861"})
+			require.NoError(t, err)
+			db.Discard(txn)
+
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func(i int) {
+					defer wg.Done()
+					require.NoError(t, db.Insert(&pb.Person{Name: "jacky", Phone: fmt.Sprint(i)}))
+				}(i)
+			}
+			wg.Wait()
+			ids := map[uint64]bool{}
+			err = db.Foreach(&pb.Person{}, func(row IRow) error {
+				ids[row.(*pb.Person).Id] = true
+				return nil
+			})
+			require.NoError(t, err)
+			for id := uint64(1); id <= 20; id++ {
+				require.True(t, ids[id], id)
+			}
+
+			//ids after a caller id continue from it, a discarded caller id is not skipped
+			txn = db.Begin(true)
+			err = db.TxInsertWithId(txn, &pb.Person{Id: 50, Name: "rose", Phone: "+862"})
+			require.NoError(t, err)
+			db.Discard(txn)
+			err = db.InsertWithId(&pb.Person{Id: 22, Name: "rose", Phone: "+862"})
+			require.NoError(t, err)
+			err = db.InsertWithId(&pb.Person{Id: 10, Name: "rose", Phone: "+863"})
+			require.ErrorIs(t, err, ErrRowIdRepeat)
+			for i := 0; i < 3; i++ {
+				person := &pb.Person{Name: "lily", Phone: fmt.Sprint(100 + i)}
+				err = db.Insert(person)
+				require.NoError(t, err)
+				require.Equal(t, uint64(23+i), person.Id)
+			}
+		})
+	})
+}
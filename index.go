@@ -1,7 +1,16 @@
 package borm
 
 import (
-	"unsafe"
+	"bytes"
+	"math"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/gogo/protobuf/types"
+	"golang.org/x/text/unicode/norm"
 )
 
 type IndexType string
@@ -10,78 +19,378 @@ const (
 	UNIQUE IndexType = "unique"
 	NORMAL IndexType = "normal"
 	UNION  IndexType = "union"
+	//inverted index of the words of a string field
+	FULLTEXT IndexType = "fulltext"
 )
 
-func getIndex(inputIndex string) (IndexType, error) {
-	switch IndexType(inputIndex) {
-	case UNIQUE:
-		return UNIQUE, nil
-	case NORMAL, UNION:
-		return NORMAL, nil
-	}
-	return "", ErrIdxNotSupport
+const (
+	//modifier of a union declaration that allows many rows per key
+	unionMulti = "multi"
+)
+
+//unionIndex
+//a composite index over fields in struct order, the default union of a
+//plain `idx:"union"` tag is named "union"
+type unionIndex struct {
+	name   string
+	fields []uint32
+	unique bool
+}
+
+type unionDecl struct {
+	name   string
+	unique bool
 }
 
-func checkIsUnion(inputIndex string) bool {
-	return UNION == IndexType(inputIndex)
+const (
+	//modifier of a unique or normal declaration that indexes the case folded string
+	modifierCaseInsensitive = "ci"
+)
+
+//fieldIndexDecl
+//the parsed idx tag of a field
+type fieldIndexDecl struct {
+	indexType       IndexType
+	unions          []unionDecl
+	caseInsensitive bool
+}
+
+//parseIndexTag
+//entries are separated by ';' and modifiers by ',', e.g. `idx:"unique,ci;union=acct_order;union=pos,multi"`,
+//fields only declared in unions also get a normal index
+func parseIndexTag(tagStr string) (*fieldIndexDecl, error) {
+	decl := &fieldIndexDecl{}
+	for _, entry := range strings.Split(tagStr, ";") {
+		parts := strings.Split(strings.TrimSpace(entry), ",")
+		name := strings.TrimSpace(parts[0])
+		switch {
+		case IndexType(name) == FULLTEXT:
+			if decl.indexType != "" || len(parts) > 1 {
+				return nil, ErrIdxNotSupport
+			}
+			decl.indexType = FULLTEXT
+		case IndexType(name) == UNIQUE || IndexType(name) == NORMAL:
+			if decl.indexType != "" {
+				return nil, ErrIdxNotSupport
+			}
+			decl.indexType = IndexType(name)
+			for _, modifier := range parts[1:] {
+				if strings.TrimSpace(modifier) != modifierCaseInsensitive {
+					return nil, ErrIdxNotSupport
+				}
+				decl.caseInsensitive = true
+			}
+		case IndexType(name) == UNION || strings.HasPrefix(name, string(UNION)+"="):
+			union := unionDecl{name: string(UNION), unique: true}
+			if name != string(UNION) {
+				union.name = strings.TrimPrefix(name, string(UNION)+"=")
+			}
+			for _, modifier := range parts[1:] {
+				if strings.TrimSpace(modifier) != unionMulti {
+					return nil, ErrIdxNotSupport
+				}
+				union.unique = false
+			}
+			if union.name == "" || strings.ContainsAny(union.name, ":=") {
+				return nil, ErrIdxNotSupport
+			}
+			decl.unions = append(decl.unions, union)
+		default:
+			return nil, ErrIdxNotSupport
+		}
+	}
+	if decl.indexType == "" {
+		decl.indexType = NORMAL
+	}
+	return decl, nil
 }
 
 type tag struct {
-	offset    uintptr
+	//index of the field in the row struct, unused by path tags
+	index     int
 	fieldType FieldType
 	indexType IndexType
 	fieldName string
+	//set while CreateIndex is backfilling, writes maintain the index but queries skip it
+	building int32
+	//field names from the row struct of a path index, nil for plain fields
+	path []string
+	//a partial index only holds the rows matching the predicate and is named by indexName
+	indexName string
+	predicate *indexPredicate
+	//string values are indexed and queried in their folded form
+	caseInsensitive bool
+}
+
+//normalize
+//the canonical index value of a query value
+func (tag *tag) normalize(val any) (any, error) {
+	val, err := normalizeIndexValue(tag.fieldType, val)
+	if err != nil || !tag.caseInsensitive {
+		return val, err
+	}
+	return foldString(val.(string)), nil
+}
+
+//foldPrefix
+//the folded form of a prefix, trailing spaces are kept
+func foldPrefix(s string) string {
+	return strings.TrimLeftFunc(strings.ToLower(norm.NFKC.String(s)), unicode.IsSpace)
+}
+
+//foldString
+//NFKC normalized, lower cased and trimmed, so " ＬＢ" and "lb" are the same
+func foldString(s string) string {
+	return strings.TrimSpace(strings.ToLower(norm.NFKC.String(s)))
+}
+
+//name
+//the name of the index, the field name except for partial indexes
+func (tag *tag) name() string {
+	if tag.indexName != "" {
+		return tag.indexName
+	}
+	return tag.fieldName
+}
+
+//matchRow
+//whether the row belongs to the index
+func (tag *tag) matchRow(row IRow) bool {
+	return tag.predicate == nil || tag.predicate.match(row)
+}
+
+//indexPredicate
+//rows whose field is one of values, values are normalized to the field type
+type indexPredicate struct {
+	tag    *tag
+	values []any
+}
+
+func newIndexPredicate(rowType reflect.Type, fieldName string, values []any) (*indexPredicate, error) {
+	if len(values) == 0 {
+		return nil, ErrIdxNotSupport
+	}
+	predicateTag, _, err := newIndexTag(rowType, fieldName, "")
+	if err != nil {
+		return nil, err
+	}
+	predicate := &indexPredicate{tag: predicateTag}
+	for _, val := range values {
+		val, err := normalizeIndexValue(predicateTag.fieldType, val)
+		if err != nil {
+			return nil, err
+		}
+		predicate.values = append(predicate.values, val)
+	}
+	return predicate, nil
+}
+
+func (p *indexPredicate) match(row IRow) bool {
+	for _, val := range p.tag.rowValues(row) {
+		if p.contains(val) {
+			return true
+		}
+	}
+	return false
+}
+
+//implied
+//whether a query with field equal to val only matches rows of the predicate
+func (p *indexPredicate) implied(val any) bool {
+	val, err := normalizeIndexValue(p.tag.fieldType, val)
+	return err == nil && p.contains(val)
+}
+
+func (p *indexPredicate) contains(val any) bool {
+	for _, v := range p.values {
+		if indexValueEqual(v, val) {
+			return true
+		}
+	}
+	return false
+}
+
+//indexValueEqual
+//equality of normalized index values
+func indexValueEqual(a, b any) bool {
+	switch v := a.(type) {
+	case []byte:
+		o, ok := b.([]byte)
+		return ok && bytes.Equal(v, o)
+	case time.Time:
+		o, ok := b.(time.Time)
+		return ok && v.Equal(o)
+	}
+	return a == b
+}
+
+//newIndexTag
+//the tag of a scalar struct field, or of a field path like newPathTag,
+//fieldIdx is 0 for path tags
+func newIndexTag(rowType reflect.Type, fieldName string, indexType IndexType) (*tag, uint32, error) {
+	structType := rowType
+	if structType.Kind() == reflect.Ptr {
+		structType = rowStructType(structType)
+	}
+	field, ok := structType.FieldByName(fieldName)
+	if ok && len(field.Index) == 1 && field.Type.Kind() != reflect.Map && field.Type.Kind() != reflect.Slice {
+		if field.Index[0] == 0 {
+			return nil, 0, ErrIdxNotSupport
+		}
+		tag, err := GetTag(field.Name, reflect.Zero(field.Type).Interface(), field.Index[0], indexType)
+		if err != nil {
+			return nil, 0, err
+		}
+		return tag, uint32(field.Index[0]), nil
+	}
+	tag, err := newPathTag(structType, fieldName, indexType)
+	if err != nil {
+		return nil, 0, err
+	}
+	return tag, 0, nil
+}
+
+//path indexes are keyed by ids after the struct fields, so their entries use the
+//same key layout as the field indexes
+const pathIdxBase uint32 = 1 << 16
+
+//newPathTag
+//the tag of an index over a field path like `AccountProperties.MainCurrency`, a map
+//on the path yields its values or, at the end of the path, its keys, a repeated field yields its elements
+func newPathTag(rowType reflect.Type, path string, indexType IndexType) (*tag, error) {
+	names := strings.Split(path, ".")
+	t := rowType
+	for i := 0; ; {
+		if i == len(names) {
+			if _, ok := fieldTypeOf(t); ok {
+				break
+			}
+		}
+		switch t.Kind() {
+		case reflect.Ptr:
+			t = t.Elem()
+		case reflect.Struct:
+			if i == len(names) {
+				return nil, ErrIdxNotSupport
+			}
+			field, ok := t.FieldByName(names[i])
+			if !ok || len(field.Index) != 1 || !field.IsExported() {
+				return nil, ErrFieldNotFound
+			}
+			t = field.Type
+			i++
+		case reflect.Map:
+			if i == len(names) {
+				t = t.Key()
+			} else {
+				t = t.Elem()
+			}
+		case reflect.Slice, reflect.Array:
+			t = t.Elem()
+		default:
+			if i != len(names) {
+				return nil, ErrFieldNotFound
+			}
+			return nil, ErrIdxNotSupport
+		}
+	}
+	tag, err := GetTag(path, reflect.Zero(t).Interface(), 0, indexType)
+	if err != nil {
+		return nil, err
+	}
+	tag.path = names
+	return tag, nil
 }
 
-func (tag *tag) GetPointerVal(p unsafe.Pointer) interface{} {
-	var val interface{}
-	switch tag.fieldType {
-	case String:
-		val = *(*string)(p)
-	case Int:
-		val = *(*int)(p)
-	case Int8:
-		val = *(*int8)(p)
-	case Int16:
-		val = *(*int16)(p)
-	case Int32:
-		val = *(*int32)(p)
-	case Int64:
-		val = *(*int64)(p)
-	case Uint:
-		val = *(*uint)(p)
-	case Uint8:
-		val = *(*uint8)(p)
-	case Uint16:
-		val = *(*uint16)(p)
-	case Uint32:
-		val = *(*uint32)(p)
-	case Uint64:
-		val = *(*uint64)(p)
-	case Float32:
-		val = *(*float32)(p)
-	case Float64:
-		val = *(*float64)(p)
-	case Complex64:
-		val = *(*complex64)(p)
-	case Complex128:
-		val = *(*complex128)(p)
-	case Byte:
-		val = *(*byte)(p)
-	case Rune:
-		val = *(*rune)(p)
-	}
-	return val
+//rowValues
+//the values of the indexed field of the row as stored, a path index has none when the path
+//crosses a nil pointer and many over maps and repeated fields
+func (tag *tag) rowValues(row IRow) []any {
+	if tag.path == nil {
+		return []any{tag.fieldValue(rowStruct(row).Field(tag.index))}
+	}
+	vals := appendPathValues(nil, rowStruct(row), tag.path)
+	for i := 0; i < len(vals); {
+		val, err := normalizeIndexValue(tag.fieldType, vals[i])
+		if err != nil {
+			vals = append(vals[:i], vals[i+1:]...)
+			continue
+		}
+		vals[i] = val
+		i++
+	}
+	return vals
+}
+
+//indexValues
+//the values of the row in the form of the index keys and the query conditions,
+//strings of a case insensitive index are folded
+func (tag *tag) indexValues(row IRow) []any {
+	vals := tag.rowValues(row)
+	if tag.caseInsensitive {
+		for i, val := range vals {
+			vals[i] = foldString(val.(string))
+		}
+	}
+	return vals
+}
+
+func appendPathValues(vals []any, v reflect.Value, path []string) []any {
+	if len(path) == 0 && v.IsValid() {
+		if _, ok := fieldTypeOf(v.Type()); ok {
+			return append(vals, v.Interface())
+		}
+	}
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return vals
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		if len(path) == 0 {
+			return vals
+		}
+		return appendPathValues(vals, v.FieldByName(path[0]), path[1:])
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if len(path) == 0 {
+				vals = append(vals, iter.Key().Interface())
+			} else {
+				vals = appendPathValues(vals, iter.Value(), path)
+			}
+		}
+		return vals
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			vals = appendPathValues(vals, v.Index(i), path)
+		}
+		return vals
+	}
+	if len(path) == 0 {
+		vals = append(vals, v.Interface())
+	}
+	return vals
 }
 
 func (tag *tag) CheckIsNormal() bool {
 	return tag.indexType == NORMAL
 }
 
+func (tag *tag) CheckIsFulltext() bool {
+	return tag.indexType == FULLTEXT
+}
+
 func (tag *tag) CheckIsUnique() bool {
 	return tag.indexType == UNIQUE
 }
 
+func (tag *tag) CheckIsReady() bool {
+	return atomic.LoadInt32(&tag.building) == 0
+}
+
 type FieldType string
 
 const (
@@ -102,166 +411,357 @@ const (
 	Complex128 FieldType = `complex128`
 	Byte       FieldType = `byte`
 	Rune       FieldType = `rune`
+	Bool       FieldType = `bool`
+	Bytes      FieldType = `[]byte`
+	Time       FieldType = `time.Time`
+	//*types.Timestamp fields, indexed and compared as time.Time
+	Timestamp FieldType = `types.Timestamp`
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	timestampType = reflect.TypeOf(types.Timestamp{})
+	bytesType     = reflect.TypeOf([]byte{})
 )
 
-func GetTag(fieldName string, dest interface{}, offset uintptr, indexType IndexType) (*tag, error) {
+//fieldKinds
+//named types like protobuf enums are indexed as their underlying kind
+var fieldKinds = map[reflect.Kind]FieldType{
+	reflect.String:     String,
+	reflect.Int:        Int,
+	reflect.Int8:       Int8,
+	reflect.Int16:      Int16,
+	reflect.Int32:      Int32,
+	reflect.Int64:      Int64,
+	reflect.Uint:       Uint,
+	reflect.Uint8:      Uint8,
+	reflect.Uint16:     Uint16,
+	reflect.Uint32:     Uint32,
+	reflect.Uint64:     Uint64,
+	reflect.Float32:    Float32,
+	reflect.Float64:    Float64,
+	reflect.Complex64:  Complex64,
+	reflect.Complex128: Complex128,
+	reflect.Bool:       Bool,
+}
+
+var fieldGoTypes = map[FieldType]reflect.Type{
+	String:     reflect.TypeOf(""),
+	Int:        reflect.TypeOf(int(0)),
+	Int8:       reflect.TypeOf(int8(0)),
+	Int16:      reflect.TypeOf(int16(0)),
+	Int32:      reflect.TypeOf(int32(0)),
+	Int64:      reflect.TypeOf(int64(0)),
+	Uint:       reflect.TypeOf(uint(0)),
+	Uint8:      reflect.TypeOf(uint8(0)),
+	Uint16:     reflect.TypeOf(uint16(0)),
+	Uint32:     reflect.TypeOf(uint32(0)),
+	Uint64:     reflect.TypeOf(uint64(0)),
+	Float32:    reflect.TypeOf(float32(0)),
+	Float64:    reflect.TypeOf(float64(0)),
+	Complex64:  reflect.TypeOf(complex64(0)),
+	Complex128: reflect.TypeOf(complex128(0)),
+	Byte:       reflect.TypeOf(byte(0)),
+	Rune:       reflect.TypeOf(rune(0)),
+	Bool:       reflect.TypeOf(false),
+}
+
+//fieldTypeOf
+//the field type indexing values of t
+func fieldTypeOf(t reflect.Type) (FieldType, bool) {
+	switch t {
+	case timeType:
+		return Time, true
+	case reflect.PtrTo(timestampType):
+		return Timestamp, true
+	case bytesType:
+		return Bytes, true
+	}
+	fieldType, ok := fieldKinds[t.Kind()]
+	return fieldType, ok
+}
+
+//normalizeIndexValue
+//convert val to the canonical value of fieldType, so that the stored values and the query
+//values of a field have the same key encoding and compare equal, e.g. pb.Gender_women
+//and 1 are int32(1), times are in UTC
+func normalizeIndexValue(fieldType FieldType, val any) (any, error) {
+	switch fieldType {
+	case Time, Timestamp:
+		switch v := val.(type) {
+		case time.Time:
+			return v.UTC(), nil
+		case *time.Time:
+			if v == nil {
+				return time.Time{}, nil
+			}
+			return v.UTC(), nil
+		case *types.Timestamp:
+			return timestampToTime(v), nil
+		case types.Timestamp:
+			return timestampToTime(&v), nil
+		}
+		return nil, ErrQueryInvalid
+	case Bytes:
+		switch v := val.(type) {
+		case []byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		}
+		return nil, ErrQueryInvalid
+	}
+	target, ok := fieldGoTypes[fieldType]
+	if !ok {
+		return nil, ErrIdxNotSupport
+	}
+	v := reflect.ValueOf(val)
+	if !v.IsValid() {
+		return nil, ErrQueryInvalid
+	}
+	if v.Type() == target {
+		return val, nil
+	}
+	if !v.CanConvert(target) || fieldKindClass(v.Kind()) != fieldKindClass(target.Kind()) {
+		return nil, ErrQueryInvalid
+	}
+	out := v.Convert(target)
+	if fieldKindClass(target.Kind()) == 4 && !sameNumber(v, out) {
+		//21.7, -1 or 300 would match 21, the max uint or 44
+		return nil, ErrQueryInvalid
+	}
+	return out.Interface(), nil
+}
+
+//sameNumber
+//whether the converted number out is in and not truncated or wrapped, floats narrowed to
+//float32 only need to be in range, the field stores them rounded the same way
+func sameNumber(in, out reflect.Value) bool {
+	if out.Kind() == reflect.Float32 && in.CanFloat() {
+		f := in.Float()
+		return math.IsNaN(f) || math.IsInf(f, 0) || math.Abs(f) <= math.MaxFloat32
+	}
+	if out.Convert(in.Type()).Interface() != in.Interface() {
+		return false
+	}
+	return isNegative(in) == isNegative(out)
+}
+
+func isNegative(v reflect.Value) bool {
+	switch {
+	case v.CanInt():
+		return v.Int() < 0
+	case v.CanFloat():
+		return v.Float() < 0
+	}
+	return false
+}
+
+//fieldKindClass
+//values only convert within numbers, strings and bools
+func fieldKindClass(kind reflect.Kind) int {
+	switch kind {
+	case reflect.String:
+		return 1
+	case reflect.Bool:
+		return 2
+	case reflect.Complex64, reflect.Complex128:
+		return 3
+	}
+	if isIntegerKind(kind) || kind == reflect.Float32 || kind == reflect.Float64 {
+		return 4
+	}
+	return 0
+}
+
+//timestampToTime
+//a nil timestamp is the zero time
+func timestampToTime(ts *types.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return time.Unix(ts.Seconds, int64(ts.Nanos)).UTC()
+}
+
+func GetTag(fieldName string, dest interface{}, index int, indexType IndexType) (*tag, error) {
 	if dest == nil {
 		return nil, ErrIdxNotSupport
 	}
 	tag := &tag{}
 	switch dest.(type) {
 	case string:
-		tag = getStringTag(offset, indexType)
+		tag = getStringTag(index, indexType)
 	case int:
-		tag = getIntTag(offset, indexType)
+		tag = getIntTag(index, indexType)
 	case int8:
-		tag = getInt8Tag(offset, indexType)
+		tag = getInt8Tag(index, indexType)
 	case int16:
-		tag = getInt16Tag(offset, indexType)
+		tag = getInt16Tag(index, indexType)
 	case int32:
-		tag = getInt32Tag(offset, indexType)
+		tag = getInt32Tag(index, indexType)
 	case int64:
-		tag = getInt64Tag(offset, indexType)
+		tag = getInt64Tag(index, indexType)
 	case uint:
-		tag = getUintTag(offset, indexType)
+		tag = getUintTag(index, indexType)
 	case uint8:
-		tag = getUint8Tag(offset, indexType)
+		tag = getUint8Tag(index, indexType)
 	case uint16:
-		tag = getUint16Tag(offset, indexType)
+		tag = getUint16Tag(index, indexType)
 	case uint32:
-		tag = getUint32Tag(offset, indexType)
+		tag = getUint32Tag(index, indexType)
 	case uint64:
-		tag = getUint64Tag(offset, indexType)
+		tag = getUint64Tag(index, indexType)
 	case float32:
-		tag = getFloat32Tag(offset, indexType)
+		tag = getFloat32Tag(index, indexType)
 	case float64:
-		tag = getFloat64Tag(offset, indexType)
+		tag = getFloat64Tag(index, indexType)
 	case complex64:
-		tag = getComplex64Tag(offset, indexType)
+		tag = getComplex64Tag(index, indexType)
 	case complex128:
-		tag = getComplex128Tag(offset, indexType)
+		tag = getComplex128Tag(index, indexType)
+	case bool:
+		tag = getBoolTag(index, indexType)
 	default:
-		return nil, ErrIdxNotSupport
+		//[]byte, time.Time, *types.Timestamp and named types like protobuf enums
+		fieldType, ok := fieldTypeOf(reflect.TypeOf(dest))
+		if !ok {
+			return nil, ErrIdxNotSupport
+		}
+		tag = getFieldTypeTag(index, fieldType, indexType)
 	}
 	tag.fieldName = fieldName
 	return tag, nil
 }
 
-func getStringTag(offset uintptr, indexType IndexType) *tag {
+func getStringTag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: String,
 		indexType: indexType,
 	}
 }
-func getIntTag(offset uintptr, indexType IndexType) *tag {
+func getIntTag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Int,
 		indexType: indexType,
 	}
 }
-func getInt8Tag(offset uintptr, indexType IndexType) *tag {
+func getInt8Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Int8,
 		indexType: indexType,
 	}
 }
-func getInt16Tag(offset uintptr, indexType IndexType) *tag {
+func getInt16Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Int16,
 		indexType: indexType,
 	}
 }
-func getInt32Tag(offset uintptr, indexType IndexType) *tag {
+func getInt32Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Int32,
 		indexType: indexType,
 	}
 }
-func getInt64Tag(offset uintptr, indexType IndexType) *tag {
+func getInt64Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Int64,
 		indexType: indexType,
 	}
 }
-func getUintTag(offset uintptr, indexType IndexType) *tag {
+func getUintTag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Uint,
 		indexType: indexType,
 	}
 }
-func getUint8Tag(offset uintptr, indexType IndexType) *tag {
+func getUint8Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Uint8,
 		indexType: indexType,
 	}
 }
-func getUint16Tag(offset uintptr, indexType IndexType) *tag {
+func getUint16Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Uint16,
 		indexType: indexType,
 	}
 }
-func getUint32Tag(offset uintptr, indexType IndexType) *tag {
+func getUint32Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Uint32,
 		indexType: indexType,
 	}
 }
-func getUint64Tag(offset uintptr, indexType IndexType) *tag {
+func getUint64Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Uint64,
 		indexType: indexType,
 	}
 }
-func getFloat32Tag(offset uintptr, indexType IndexType) *tag {
+func getFloat32Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Float32,
 		indexType: indexType,
 	}
 }
-func getFloat64Tag(offset uintptr, indexType IndexType) *tag {
+func getFloat64Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Float64,
 		indexType: indexType,
 	}
 }
-func getComplex64Tag(offset uintptr, indexType IndexType) *tag {
+func getComplex64Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Complex64,
 		indexType: indexType,
 	}
 }
-func getComplex128Tag(offset uintptr, indexType IndexType) *tag {
+func getComplex128Tag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Complex128,
 		indexType: indexType,
 	}
 }
-func getByteTag(offset uintptr, indexType IndexType) *tag {
+func getByteTag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Byte,
 		indexType: indexType,
 	}
 }
-func getRuneTag(offset uintptr, indexType IndexType) *tag {
+func getFieldTypeTag(index int, fieldType FieldType, indexType IndexType) *tag {
+	return &tag{
+		index:     index,
+		fieldType: fieldType,
+		indexType: indexType,
+	}
+}
+func getBoolTag(index int, indexType IndexType) *tag {
+	return &tag{
+		index:     index,
+		fieldType: Bool,
+		indexType: indexType,
+	}
+}
+func getRuneTag(index int, indexType IndexType) *tag {
 	return &tag{
-		offset:    offset,
+		index:     index,
 		fieldType: Rune,
 		indexType: indexType,
 	}
@@ -0,0 +1,190 @@
+package borm
+
+import (
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/longbridgeapp/borm/common"
+
+	badger "github.com/dgraph-io/badger/v3"
+)
+
+//CreateIndex
+//add a unique, normal or fulltext index to an existing table, existing rows are indexed in
+//batches while writes continue, the index is used by queries once the build completes,
+//fieldName may be a path like WithPathIndex, write txns started before the call fail to
+//commit with ErrConflict
+func (bormDb *BormDb) CreateIndex(row IRow, fieldName string, indexType IndexType) error {
+	if indexType != UNIQUE && indexType != NORMAL && indexType != FULLTEXT {
+		return ErrIdxNotSupport
+	}
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return err
+	}
+	indexTag, fieldIdx, err := newIndexTag(reflect.TypeOf(row), fieldName, indexType)
+	if err != nil {
+		return err
+	}
+	if indexType == FULLTEXT && indexTag.fieldType != String {
+		return ErrIdxNotSupport
+	}
+	indexTag.building = 1
+	if indexTag.path == nil {
+		err = bormDb.tableManager.AddIndexTag(tableId, fieldIdx, indexTag)
+	} else {
+		fieldIdx, err = bormDb.tableManager.AddPathIndexTag(tableId, indexTag)
+	}
+	if err != nil {
+		return err
+	}
+	//rows written before the bump are read by the backfill, later writes see the tag
+	err = bormDb.bumpSchema(tableId)
+	if err == nil {
+		err = bormDb.backfillIndex(tableId, fieldIdx, indexTag, row)
+	}
+	if err != nil {
+		if dropErr := bormDb.removeIndex(tableId, fieldIdx, indexTag); dropErr != nil {
+			bormDb.optConfig.Logger.Errorf("Drop index entries of failed build error,%v\n", dropErr)
+		}
+		return err
+	}
+	atomic.StoreInt32(&indexTag.building, 0)
+	return nil
+}
+
+//DropIndex
+//remove a unique or normal index by its field name, or a partial index by its name,
+//fields of the union index can not be dropped, write txns started before the call fail
+//to commit with ErrConflict
+func (bormDb *BormDb) DropIndex(row IRow, fieldName string) error {
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return err
+	}
+	for fieldIdx, tag := range bormDb.tableManager.GetIndexTags(tableId) {
+		if tag.name() != fieldName {
+			continue
+		}
+		if bormDb.tableManager.IsUnionField(tableId, fieldIdx) {
+			return ErrIdxNotSupport
+		}
+		return bormDb.removeIndex(tableId, fieldIdx, tag)
+	}
+	return ErrIdxNotSupport
+}
+
+//removeIndex
+//unpublish the tag, then drop the entries after the schema bump, so write txns that still
+//maintain the index can't commit entries after they are dropped
+func (bormDb *BormDb) removeIndex(tableId, fieldIdx uint32, tag *tag) error {
+	bormDb.tableManager.RemoveIndexTag(tableId, fieldIdx)
+	err := bormDb.bumpSchema(tableId)
+	if err != nil {
+		return err
+	}
+	return bormDb.dropIndexEntries(tableId, fieldIdx, tag)
+}
+
+//readSchema
+//track the schema key of the table in the writing txn, see bumpSchema
+func readSchema(txn *badger.Txn, tableId uint32) error {
+	_, err := txn.Get(encodeSchemaKey(tableId))
+	if err == badger.ErrKeyNotFound {
+		return nil
+	}
+	return err
+}
+
+//bumpSchema
+//rewrite the schema key after the index tags of the table changed, writes read the key, so a
+//txn started before the bump fails to commit with ErrConflict instead of committing index entries
+//of the old tags, txns started after the bump see the new tags
+func (bormDb *BormDb) bumpSchema(tableId uint32) error {
+	return bormDb.db.Update(func(txn *badger.Txn) error {
+		return txn.Set(encodeSchemaKey(tableId), common.EncodedFromUInt64(uint64(time.Now().UnixNano())))
+	})
+}
+
+func (bormDb *BormDb) backfillIndex(tableId, fieldIdx uint32, tag *tag, row IRow) error {
+	prefix := encodeTablePrefixKey(tableId)
+	seek := prefix
+	for {
+		var next []byte
+		err := bormDb.db.Update(func(txn *badger.Txn) error {
+			it := txn.NewIterator(badger.DefaultIteratorOptions)
+			defer it.Close()
+			n := 0
+			for it.Seek(seek); it.ValidForPrefix(prefix); it.Next() {
+				item := it.Item()
+				if n == bormDb.optConfig.IndexBuildBatchSize {
+					next = item.KeyCopy(nil)
+					return nil
+				}
+				var tp IRow
+				err := item.Value(func(val []byte) (err error) {
+					tp, err = bormDb.tableManager.Unmarshal(tableId, row, val)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				if !tag.matchRow(tp) {
+					n++
+					continue
+				}
+				for _, val := range tag.indexValues(tp) {
+					err = bormDb.createFieldIndex(tableId, fieldIdx, tag, val, txn, rowId(tp), item.ExpiresAt())
+					if err != nil {
+						return err
+					}
+				}
+				n++
+			}
+			return nil
+		})
+		if err == badger.ErrConflict {
+			bormDb.optConfig.Logger.Warningf("Txn backfill index conflict,table=%v,field=%v\n", tableId, tag.fieldName)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if next == nil {
+			return nil
+		}
+		seek = next
+	}
+}
+
+func (bormDb *BormDb) dropIndexEntries(tableId, fieldIdx uint32, tag *tag) error {
+	prefix := indexKeyPrefix(tableId, fieldIdx, tag)
+	for {
+		keys := [][]byte{}
+		err := bormDb.db.Update(func(txn *badger.Txn) error {
+			opt := badger.DefaultIteratorOptions
+			opt.PrefetchValues = false
+			it := txn.NewIterator(opt)
+			for it.Seek(prefix); it.ValidForPrefix(prefix) && len(keys) < bormDb.optConfig.IndexBuildBatchSize; it.Next() {
+				keys = append(keys, it.Item().KeyCopy(nil))
+			}
+			it.Close()
+			for _, key := range keys {
+				if err := txn.Delete(key); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err == badger.ErrConflict {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return nil
+		}
+	}
+}
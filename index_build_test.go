@@ -0,0 +1,168 @@
+package borm
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/longbridgeapp/borm/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnlineIndex(t *testing.T) {
+	t.Run("create index", func(t *testing.T) {
+		db, err := New(WithIndexBuildBatchSize(100))
+		require.NoError(t, err)
+		defer db.Close()
+		err = db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		for i := 0; i < 1000; i++ {
+			err = db.Insert(&pb.Person{Name: "jacky", Phone: fmt.Sprintf("+86%d", i), Age: 30, BirthDay: uint32(i % 10)})
+			require.NoError(t, err)
+		}
+		_, err = Find(db, WithAnd(&pb.Person{}).Eq("BirthDay", uint32(1)))
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+
+		wg := sync.WaitGroup{}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 1000; i < 1500; i++ {
+				err := db.Insert(&pb.Person{Name: "jacky", Phone: fmt.Sprintf("+86%d", i), Age: 30, BirthDay: uint32(i % 10)})
+				require.NoError(t, err)
+			}
+		}()
+		err = db.CreateIndex(&pb.Person{}, "BirthDay", NORMAL)
+		require.NoError(t, err)
+		wg.Wait()
+
+		err = db.CreateIndex(&pb.Person{}, "BirthDay", NORMAL)
+		require.ErrorIs(t, err, ErrIdxRepeat)
+		err = db.CreateIndex(&pb.Person{}, "Birth", NORMAL)
+		require.ErrorIs(t, err, ErrFieldNotFound)
+
+		persons, err := Find(db, WithAnd(&pb.Person{}).Eq("BirthDay", uint32(1)))
+		require.NoError(t, err)
+		require.Equal(t, 150, len(persons))
+		detail, err := db.Snoop(&pb.Person{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(1500), detail.TotalCount)
+		require.Equal(t, uint64(1500), detail.NormalIndex["BirthDay"])
+
+		//unique build fails on duplicates and leaves nothing behind
+		err = db.DropIndex(&pb.Person{}, "BirthDay")
+		require.NoError(t, err)
+		err = db.CreateIndex(&pb.Person{}, "BirthDay", UNIQUE)
+		require.ErrorIs(t, err, ErrIdxUniqueConflict)
+		_, err = Find(db, WithAnd(&pb.Person{}).Eq("BirthDay", uint32(1)))
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+		detail, err = db.Snoop(&pb.Person{})
+		require.NoError(t, err)
+		require.Equal(t, 1, len(detail.UniqueIndex))
+		require.Equal(t, 2, len(detail.NormalIndex))
+		err = db.View(func(txn *badger.Txn) error {
+			require.Equal(t, uint64(0), db.countWithPrefix(txn, encodeUqIndexKeyPrefix(0, 4)))
+			require.Equal(t, uint64(0), db.countWithPrefix(txn, encodeNormalIndexPrefix(0, 4)))
+			return nil
+		})
+		require.NoError(t, err)
+	})
+
+	t.Run("batch size", func(t *testing.T) {
+		_, err := New(WithIndexBuildBatchSize(0))
+		require.ErrorIs(t, err, ErrOptionIllegal)
+		_, err = New(WithIndexBuildBatchSize(-1))
+		require.ErrorIs(t, err, ErrOptionIllegal)
+	})
+
+	t.Run("txns started before the build", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			err := db.CreateTable(&pb.Person{})
+			require.NoError(t, err)
+			for i := 0; i < 10; i++ {
+				err = db.Insert(&pb.Person{Name: "jacky", Phone: fmt.Sprintf("+86%d", i), Age: 30, BirthDay: uint32(i)})
+				require.NoError(t, err)
+			}
+			//an update and an insert written without the new index
+			updateTx := db.Begin(true)
+			err = db.TxUpdate(updateTx, 1, &pb.Person{Name: "jacky", Phone: "+860", Age: 30, BirthDay: 100})
+			require.NoError(t, err)
+			insertTx := db.Begin(true)
+			err = db.TxInsert(insertTx, &pb.Person{Name: "jim", Phone: "+8610", Age: 30, BirthDay: 10})
+			require.NoError(t, err)
+
+			err = db.CreateIndex(&pb.Person{}, "BirthDay", UNIQUE)
+			require.NoError(t, err)
+			require.ErrorIs(t, db.Commit(updateTx), ErrConflict)
+			require.ErrorIs(t, db.Commit(insertTx), ErrConflict)
+
+			//retried with the new index
+			err = db.Update(1, &pb.Person{Name: "jacky", Phone: "+860", Age: 30, BirthDay: 100})
+			require.NoError(t, err)
+			err = db.Insert(&pb.Person{Name: "jim", Phone: "+8610", Age: 30, BirthDay: 10})
+			require.NoError(t, err)
+			_, err = First(db, WithAnd(&pb.Person{}).Eq("BirthDay", uint32(0)))
+			require.ErrorIs(t, err, ErrKeyNotFound)
+			person, err := First(db, WithAnd(&pb.Person{}).Eq("BirthDay", uint32(100)))
+			require.NoError(t, err)
+			require.Equal(t, uint64(1), person.Id)
+			//the old value is free again
+			err = db.Insert(&pb.Person{Name: "lucy", Phone: "+8611", Age: 30, BirthDay: 0})
+			require.NoError(t, err)
+			detail, err := db.Snoop(&pb.Person{})
+			require.NoError(t, err)
+			require.Equal(t, uint64(12), detail.UniqueIndex["BirthDay"])
+		})
+	})
+
+	t.Run("drop index", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			err := db.CreateTable(&pb.Order{})
+			require.NoError(t, err)
+			for i := 0; i < 100; i++ {
+				err = db.Insert(&pb.Order{AccountChannel: "lb", Aaid: uint64(i), OrderId: fmt.Sprint(i), CounterId: "ST/HK/700"})
+				require.NoError(t, err)
+			}
+			err = db.DropIndex(&pb.Order{}, "Aaid")
+			require.ErrorIs(t, err, ErrIdxNotSupport)
+			err = db.DropIndex(&pb.Order{}, "CounterId")
+			require.NoError(t, err)
+			_, err = Find(db, WithAnd(&pb.Order{}).Eq("CounterId", "ST/HK/700"))
+			require.ErrorIs(t, err, ErrIdxNotSupport)
+			err = db.View(func(txn *badger.Txn) error {
+				require.Equal(t, uint64(0), db.countWithPrefix(txn, encodeNormalIndexPrefix(0, 5)))
+				return nil
+			})
+			require.NoError(t, err)
+
+			err = db.CreateIndex(&pb.Order{}, "CounterId", UNIQUE)
+			require.ErrorIs(t, err, ErrIdxUniqueConflict)
+			err = db.CreateIndex(&pb.Order{}, "OrgId", UNIQUE)
+			require.ErrorIs(t, err, ErrIdxRepeat)
+		})
+	})
+
+	t.Run("txns started before the drop", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			err := db.CreateTable(&pb.Order{})
+			require.NoError(t, err)
+			//writes the CounterId entry of the index being dropped
+			tx := db.Begin(true)
+			err = db.TxInsert(tx, &pb.Order{AccountChannel: "lb", Aaid: 1, OrderId: "1", CounterId: "ST/HK/700"})
+			require.NoError(t, err)
+
+			err = db.DropIndex(&pb.Order{}, "CounterId")
+			require.NoError(t, err)
+			require.ErrorIs(t, db.Commit(tx), ErrConflict)
+			err = db.Insert(&pb.Order{AccountChannel: "lb", Aaid: 1, OrderId: "1", CounterId: "ST/HK/700"})
+			require.NoError(t, err)
+			err = db.View(func(txn *badger.Txn) error {
+				require.Equal(t, uint64(0), db.countWithPrefix(txn, encodeNormalIndexPrefix(0, 5)))
+				return nil
+			})
+			require.NoError(t, err)
+		})
+	})
+}
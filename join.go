@@ -0,0 +1,287 @@
+package borm
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v3"
+)
+
+//JoinResult
+//a left row paired with a matching right row
+type JoinResult[L IRow, R IRow] struct {
+	Left  L
+	Right R
+}
+
+//JoinStrategy
+//how the right rows of a join are found
+type JoinStrategy string
+
+const (
+	//each left row probes the unique, union or normal indexes of the right fields
+	NestedLoopJoin JoinStrategy = "NESTED LOOP"
+	//the right table is scanned once into a hash table keyed by the right fields
+	HashJoin JoinStrategy = "HASH"
+)
+
+//a hash join is used when the right table has fewer rows than this many times the left rows
+const joinHashFactor = 2
+
+//joinField
+//an on field of both tables, the left value is converted to the type of the right field
+//and folded like rightTag when it is case-insensitive, the right index for a join
+type joinField struct {
+	left       string
+	right      string
+	rightIndex []int
+	rightType  FieldType
+	rightTag   *tag
+}
+
+//normalize
+//the value compared by the join, both strategies and both sides key on it
+func (field *joinField) normalize(val any) (any, error) {
+	if field.rightTag != nil {
+		return field.rightTag.normalize(val)
+	}
+	return normalizeIndexValue(field.rightType, val)
+}
+
+//Join
+//inner join the rows of the left condition with the rows of the right table whose on fields
+//are equal, an on field is a field name of both tables or `LeftField=RightField`, pairs keep
+//the order of the left rows and the right rows of a left row are ordered by id
+func Join[L IRow, R IRow](db *BormDb, left ICompoundConditions[L], right R, on ...string) ([]JoinResult[L, R], error) {
+	var (
+		results []JoinResult[L, R]
+		err     error
+	)
+	err = db.View(func(txn *badger.Txn) error {
+		results, err = TxJoin(txn, db, left, right, on...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func TxJoin[L IRow, R IRow](txn *badger.Txn, db *BormDb, left ICompoundConditions[L], right R, on ...string) ([]JoinResult[L, R], error) {
+	rightTableName := db.tableManager.GetTableName(right)
+	rightTableId, err := db.tableManager.GetTableId(rightTableName)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := parseJoinOn(reflect.TypeOf(right), on)
+	if err != nil {
+		return nil, err
+	}
+	for i := range fields {
+		fields[i].rightTag, _ = db.tableManager.GetIndexTag(rightTableId, fields[i].right)
+	}
+	leftType := rowStructType(reflect.TypeOf(left.getRow()))
+	leftIndexes := make([][]int, len(fields))
+	for i, field := range fields {
+		structField, ok := leftType.FieldByName(field.left)
+		if !ok {
+			return nil, ErrFieldNotFound
+		}
+		leftIndexes[i] = structField.Index
+	}
+	leftRows, err := left.query(txn, db)
+	if err != nil {
+		return nil, err
+	}
+	strategy := NestedLoopJoin
+	limit := len(leftRows) * joinHashFactor
+	if !db.joinIndexed(rightTableId, fields) || db.countWithPrefixUpTo(txn, encodeTablePrefixKey(rightTableId), limit) < limit {
+		strategy = HashJoin
+	}
+	results := []JoinResult[L, R]{}
+	if db.optConfig.QueryAnalyzer {
+		start := time.Now()
+		leftTableName := db.tableManager.GetTableName(left.getRow())
+		defer func() {
+			db.optConfig.Logger.Printf("[%v][%s][rows:%v]", time.Since(start), joinAnalyzer(leftTableName, rightTableName, fields, strategy), len(results))
+		}()
+	}
+	if len(leftRows) == 0 {
+		return results, nil
+	}
+	var matches map[string][]R
+	if strategy == HashJoin {
+		matches, err = hashJoinTable(txn, db, right, fields)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		matches = map[string][]R{}
+	}
+	vals := make([]any, len(fields))
+	for _, leftRow := range leftRows {
+		leftValue := rowStruct(leftRow)
+		matched := true
+		for i, field := range fields {
+			vals[i], err = field.normalize(leftValue.FieldByIndex(leftIndexes[i]).Interface())
+			if err != nil {
+				//a value the right field can't hold matches no row
+				matched = false
+				break
+			}
+		}
+		if !matched {
+			continue
+		}
+		key := joinKey(vals)
+		rightRows, ok := matches[key]
+		if !ok && strategy == NestedLoopJoin {
+			//left rows sharing the key reuse the probe
+			rightRows, err = probeJoinTable(txn, db, right, fields, vals)
+			if err != nil {
+				return nil, err
+			}
+			matches[key] = rightRows
+		}
+		for _, rightRow := range rightRows {
+			results = append(results, JoinResult[L, R]{Left: leftRow, Right: rightRow})
+		}
+	}
+	return results, nil
+}
+
+func parseJoinOn(rightType reflect.Type, on []string) ([]joinField, error) {
+	if len(on) == 0 {
+		return nil, ErrQueryInvalid
+	}
+	fields := make([]joinField, 0, len(on))
+	for _, name := range on {
+		field := joinField{left: name, right: name}
+		if i := strings.IndexByte(name, '='); i >= 0 {
+			field.left, field.right = name[:i], name[i+1:]
+		}
+		structField, ok := rowStructType(rightType).FieldByName(field.right)
+		if !ok {
+			return nil, ErrFieldNotFound
+		}
+		fieldType, ok := fieldTypeOf(structField.Type)
+		if !ok {
+			return nil, ErrIdxNotSupport
+		}
+		field.rightIndex = structField.Index
+		field.rightType = fieldType
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+//joinIndexed
+//whether every right field has a ready unique or normal index to probe
+func (bormDb *BormDb) joinIndexed(tableId uint32, fields []joinField) bool {
+	for _, field := range fields {
+		if _, err := bormDb.tableManager.GetUniqueIdx(tableId, field.right); err == nil {
+			continue
+		}
+		if _, err := bormDb.tableManager.GetNormalIdx(tableId, field.right); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+//joinKey
+//the hash key of normalized field values
+func joinKey(vals []any) string {
+	return fmt.Sprintf("%#v", vals)
+}
+
+func probeJoinTable[R IRow](txn *badger.Txn, db *BormDb, right R, fields []joinField, vals []any) ([]R, error) {
+	probe := DefaultBaseCompoundCondition[R](right)
+	for i, field := range fields {
+		probe.fieldValueMap.Set(field.right, vals[i])
+	}
+	ids, err := probe.queryRowIds(txn, db)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	rows := make([]R, 0, len(ids))
+	err = db.TxQueryWithPk(txn, right, ids, func(row IRow) error {
+		rows = append(rows, row.(R))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+func hashJoinTable[R IRow](txn *badger.Txn, db *BormDb, right R, fields []joinField) (map[string][]R, error) {
+	matches := map[string][]R{}
+	vals := make([]any, len(fields))
+	err := db.TxForeach(txn, right, func(row IRow) error {
+		rowValue := rowStruct(row)
+		for i, field := range fields {
+			val, err := field.normalize(rowValue.FieldByIndex(field.rightIndex).Interface())
+			if err != nil {
+				return err
+			}
+			vals[i] = val
+		}
+		key := joinKey(vals)
+		matches[key] = append(matches[key], row.(R))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	//rows are scanned in key order, which is not the order of the ids
+	for _, rows := range matches {
+		sort.Slice(rows, func(i, j int) bool { return rowId(rows[i]) < rowId(rows[j]) })
+	}
+	return matches, nil
+}
+
+//scanJoinTable
+//the right rows matching the normalized values, for a single lookup without an index to probe
+func scanJoinTable[R IRow](txn *badger.Txn, db *BormDb, right R, fields []joinField, vals []any) ([]R, error) {
+	key := joinKey(vals)
+	rows := []R{}
+	rowVals := make([]any, len(fields))
+	err := db.TxForeach(txn, right, func(row IRow) error {
+		rowValue := rowStruct(row)
+		for i, field := range fields {
+			val, err := field.normalize(rowValue.FieldByIndex(field.rightIndex).Interface())
+			if err != nil {
+				return err
+			}
+			rowVals[i] = val
+		}
+		if joinKey(rowVals) == key {
+			rows = append(rows, row.(R))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(rows, func(i, j int) bool { return rowId(rows[i]) < rowId(rows[j]) })
+	return rows, nil
+}
+
+//countWithPrefixUpTo
+//the number of keys with the prefix, counting stops at limit
+func (bormDb *BormDb) countWithPrefixUpTo(txn *badger.Txn, prefix []byte, limit int) int {
+	opt := badger.DefaultIteratorOptions
+	opt.PrefetchValues = false
+	it := txn.NewIterator(opt)
+	defer it.Close()
+	count := 0
+	for it.Seek(prefix); it.ValidForPrefix(prefix) && count < limit; it.Next() {
+		count++
+	}
+	return count
+}
@@ -0,0 +1,113 @@
+package borm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/longbridgeapp/borm/pb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJoin(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		logger := &recordLogger{Logger: db.optConfig.Logger}
+		db.optConfig.Logger = logger
+		err := db.CreateTable(&pb.AccountInfo{})
+		require.NoError(t, err)
+		err = db.CreateTable(&pb.Order{})
+		require.NoError(t, err)
+		for i := 1; i <= 10; i++ {
+			err = db.Insert(&pb.AccountInfo{Aaid: uint64(i), AccountChannel: "lb"})
+			require.NoError(t, err)
+		}
+		for i := 0; i < 30; i++ {
+			err = db.Insert(&pb.Order{
+				AccountChannel: "lb",
+				Aaid:           uint64(i%12 + 1),
+				OrderId:        fmt.Sprint(i),
+				Market:         "HK",
+			})
+			require.NoError(t, err)
+		}
+
+		results, err := Join(db, WithAnd(&pb.Order{}).Eq("Aaid", uint64(2)), &pb.AccountInfo{}, "AccountChannel", "Aaid")
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		for _, result := range results {
+			require.Equal(t, uint64(2), result.Left.Aaid)
+			require.Equal(t, uint64(2), result.Right.Aaid)
+		}
+		require.Contains(t, logger.lines[len(logger.lines)-1], "JOIN AccountInfo ON Order.AccountChannel=AccountInfo.AccountChannel AND Order.Aaid=AccountInfo.Aaid USING NESTED LOOP")
+
+		//orders of aaid 11 and 12 have no account
+		results, err = Join(db, WithAnd(&pb.Order{}).Eq("AccountChannel", "lb"), &pb.AccountInfo{}, "AccountChannel", "Aaid=Aaid")
+		require.NoError(t, err)
+		require.Len(t, results, 26)
+		require.Contains(t, logger.lines[len(logger.lines)-1], "USING HASH")
+		for i, result := range results {
+			require.Equal(t, result.Left.Aaid, result.Right.Aaid)
+			require.NotZero(t, result.Right.Id)
+			if i > 0 {
+				require.Greater(t, result.Left.Id, results[i-1].Left.Id)
+			}
+		}
+
+		//a right table without an index on the field is hashed
+		orders, err := Join(db, WithAnd(&pb.AccountInfo{}).Eq("Aaid", uint64(3)), &pb.Order{}, "Aaid", "AccountChannel", "AccountChannel=Market")
+		require.NoError(t, err)
+		require.Len(t, orders, 0)
+		require.Contains(t, logger.lines[len(logger.lines)-1], "USING HASH")
+		orders, err = Join(db, WithAnd(&pb.AccountInfo{}).Eq("Aaid", uint64(3)), &pb.Order{}, "Aaid")
+		require.NoError(t, err)
+		require.Len(t, orders, 3)
+		require.Less(t, orders[0].Right.Id, orders[1].Right.Id)
+
+		_, err = Join(db, WithAnd(&pb.Order{}), &pb.AccountInfo{}, "OrgId")
+		require.ErrorIs(t, err, ErrFieldNotFound)
+		_, err = Join(db, WithAnd(&pb.Order{}), &pb.AccountInfo{})
+		require.ErrorIs(t, err, ErrQueryInvalid)
+	})
+}
+
+func TestJoinCaseInsensitive(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		logger := &recordLogger{Logger: db.optConfig.Logger}
+		db.optConfig.Logger = logger
+		err := db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.CreateTable(&ciAccount{})
+		require.NoError(t, err)
+		err = db.Insert(&ciAccount{Name: "Jacky Chan", Email: "jacky@lb.com", Channel: "lb", Aaid: 1})
+		require.NoError(t, err)
+		err = db.Insert(&ciAccount{Name: "Rose", Email: "rose@lb.com", Channel: "lb", Aaid: 2})
+		require.NoError(t, err)
+		names := []string{"JACKY CHAN", " jacky chan ", "rose", "Lily"}
+		for i, name := range names {
+			err = db.Insert(&pb.Person{Name: name, Phone: fmt.Sprint(i), Age: 30})
+			require.NoError(t, err)
+		}
+
+		//the right table is small against all the persons, so it is hashed
+		results, err := Join(db, WithAnd(&pb.Person{}).Eq("Age", uint32(30)), &ciAccount{}, "Name")
+		require.NoError(t, err)
+		require.Contains(t, logger.lines[len(logger.lines)-1], "USING HASH")
+		hashed := map[uint64]uint64{}
+		for _, result := range results {
+			hashed[result.Left.Id] = result.Right.Id
+		}
+		require.Len(t, hashed, 3)
+
+		//a single person probes the ci index of Name
+		probed := map[uint64]uint64{}
+		for i := range names {
+			results, err = Join(db, WithAnd(&pb.Person{}).Eq("Phone", fmt.Sprint(i)), &ciAccount{}, "Name")
+			require.NoError(t, err)
+			require.Contains(t, logger.lines[len(logger.lines)-1], "USING NESTED LOOP")
+			for _, result := range results {
+				probed[result.Left.Id] = result.Right.Id
+			}
+		}
+		require.Equal(t, hashed, probed)
+	})
+}
@@ -1,5 +1,7 @@
 package borm
 
+import "time"
+
 type Options struct {
 	//default log level WARNING
 	Logger Logger
@@ -7,6 +9,12 @@ type Options struct {
 	MemTableSize int64
 	// default true
 	QueryAnalyzer bool
+	// default 0, released snapshots are discarded immediately
+	SnapshotRetention time.Duration
+	// default 1024, must be positive, events are dropped when a subscriber falls this far behind
+	SubscribeBufferSize int
+	// default 1000, rows backfilled or index entries removed per txn by CreateIndex and DropIndex, must be positive
+	IndexBuildBatchSize int
 }
 
 type Option func(*Options)
@@ -16,6 +24,9 @@ func newOptions(ops ...Option) *Options {
 		Logger:        defaultLogger(WARNING),
 		MemTableSize:  (64 << 20) * 8,
 		QueryAnalyzer: true,
+
+		SubscribeBufferSize: 1024,
+		IndexBuildBatchSize: 1000,
 	}
 	for _, o := range ops {
 		o(opt)
@@ -40,3 +51,21 @@ func WithQueryAnalyzer(val bool) Option {
 		o.QueryAnalyzer = val
 	}
 }
+
+func WithSnapshotRetention(val time.Duration) Option {
+	return func(o *Options) {
+		o.SnapshotRetention = val
+	}
+}
+
+func WithSubscribeBufferSize(val int) Option {
+	return func(o *Options) {
+		o.SubscribeBufferSize = val
+	}
+}
+
+func WithIndexBuildBatchSize(val int) Option {
+	return func(o *Options) {
+		o.IndexBuildBatchSize = val
+	}
+}
@@ -0,0 +1,392 @@
+// Code generated by protoc-gen-borm. DO NOT EDIT.
+// source: pb.proto
+
+package pb
+
+import "github.com/longbridgeapp/borm/typed"
+
+func (*Person) GetTableName() string {
+	return "Person"
+}
+
+func (*Person) Clone() any {
+	return &Person{}
+}
+
+// field names of Person
+const (
+	PersonFieldId       = "Id"
+	PersonFieldName     = "Name"
+	PersonFieldPhone    = "Phone"
+	PersonFieldAge      = "Age"
+	PersonFieldBirthDay = "BirthDay"
+	PersonFieldGender   = "Gender"
+)
+
+// PersonQ typed query builders of Person for borm.Where
+var PersonQ = struct {
+	Id       typed.Field[*Person, uint64]
+	Name     typed.StringField[*Person]
+	Phone    typed.StringField[*Person]
+	Age      typed.Field[*Person, uint32]
+	BirthDay typed.Field[*Person, uint32]
+	Gender   typed.Field[*Person, Gender]
+}{
+	Id:       typed.NewField[*Person, uint64](PersonFieldId),
+	Name:     typed.NewStringField[*Person](PersonFieldName),
+	Phone:    typed.NewStringField[*Person](PersonFieldPhone),
+	Age:      typed.NewField[*Person, uint32](PersonFieldAge),
+	BirthDay: typed.NewField[*Person, uint32](PersonFieldBirthDay),
+	Gender:   typed.NewField[*Person, Gender](PersonFieldGender),
+}
+
+func (*Order) GetTableName() string {
+	return "Order"
+}
+
+func (*Order) Clone() any {
+	return &Order{}
+}
+
+// field names of Order
+const (
+	OrderFieldId             = "Id"
+	OrderFieldAccountChannel = "AccountChannel"
+	OrderFieldAaid           = "Aaid"
+	OrderFieldOrderId        = "OrderId"
+	OrderFieldOrgId          = "OrgId"
+	OrderFieldCounterId      = "CounterId"
+	OrderFieldCurrency       = "Currency"
+	OrderFieldMarket         = "Market"
+	OrderFieldEntrustType    = "EntrustType"
+	OrderFieldEntrustStatus  = "EntrustStatus"
+	OrderFieldSide           = "Side"
+	OrderFieldEntrustAmount  = "EntrustAmount"
+	OrderFieldEntrustQty     = "EntrustQty"
+)
+
+// OrderQ typed query builders of Order for borm.Where
+var OrderQ = struct {
+	Id             typed.Field[*Order, uint64]
+	AccountChannel typed.StringField[*Order]
+	Aaid           typed.Field[*Order, uint64]
+	OrderId        typed.StringField[*Order]
+	OrgId          typed.StringField[*Order]
+	CounterId      typed.StringField[*Order]
+	Currency       typed.StringField[*Order]
+	Market         typed.StringField[*Order]
+	EntrustType    typed.Field[*Order, int32]
+	EntrustStatus  typed.Field[*Order, int32]
+	Side           typed.Field[*Order, int32]
+	EntrustAmount  typed.StringField[*Order]
+	EntrustQty     typed.StringField[*Order]
+}{
+	Id:             typed.NewField[*Order, uint64](OrderFieldId),
+	AccountChannel: typed.NewStringField[*Order](OrderFieldAccountChannel),
+	Aaid:           typed.NewField[*Order, uint64](OrderFieldAaid),
+	OrderId:        typed.NewStringField[*Order](OrderFieldOrderId),
+	OrgId:          typed.NewStringField[*Order](OrderFieldOrgId),
+	CounterId:      typed.NewStringField[*Order](OrderFieldCounterId),
+	Currency:       typed.NewStringField[*Order](OrderFieldCurrency),
+	Market:         typed.NewStringField[*Order](OrderFieldMarket),
+	EntrustType:    typed.NewField[*Order, int32](OrderFieldEntrustType),
+	EntrustStatus:  typed.NewField[*Order, int32](OrderFieldEntrustStatus),
+	Side:           typed.NewField[*Order, int32](OrderFieldSide),
+	EntrustAmount:  typed.NewStringField[*Order](OrderFieldEntrustAmount),
+	EntrustQty:     typed.NewStringField[*Order](OrderFieldEntrustQty),
+}
+
+func (*IllegalPerson_1) GetTableName() string {
+	return "IllegalPerson_1"
+}
+
+func (*IllegalPerson_1) Clone() any {
+	return &IllegalPerson_1{}
+}
+
+// field names of IllegalPerson_1
+const (
+	IllegalPerson_1FieldName     = "Name"
+	IllegalPerson_1FieldPhone    = "Phone"
+	IllegalPerson_1FieldAge      = "Age"
+	IllegalPerson_1FieldBirthDay = "BirthDay"
+	IllegalPerson_1FieldGender   = "Gender"
+)
+
+// IllegalPerson_1Q typed query builders of IllegalPerson_1 for borm.Where
+var IllegalPerson_1Q = struct {
+	Name     typed.StringField[*IllegalPerson_1]
+	Phone    typed.StringField[*IllegalPerson_1]
+	Age      typed.Field[*IllegalPerson_1, uint32]
+	BirthDay typed.Field[*IllegalPerson_1, uint32]
+	Gender   typed.Field[*IllegalPerson_1, Gender]
+}{
+	Name:     typed.NewStringField[*IllegalPerson_1](IllegalPerson_1FieldName),
+	Phone:    typed.NewStringField[*IllegalPerson_1](IllegalPerson_1FieldPhone),
+	Age:      typed.NewField[*IllegalPerson_1, uint32](IllegalPerson_1FieldAge),
+	BirthDay: typed.NewField[*IllegalPerson_1, uint32](IllegalPerson_1FieldBirthDay),
+	Gender:   typed.NewField[*IllegalPerson_1, Gender](IllegalPerson_1FieldGender),
+}
+
+func (*IllegalPerson_2) GetTableName() string {
+	return "IllegalPerson_2"
+}
+
+func (*IllegalPerson_2) Clone() any {
+	return &IllegalPerson_2{}
+}
+
+// field names of IllegalPerson_2
+const (
+	IllegalPerson_2FieldId       = "Id"
+	IllegalPerson_2FieldName     = "Name"
+	IllegalPerson_2FieldPhone    = "Phone"
+	IllegalPerson_2FieldAge      = "Age"
+	IllegalPerson_2FieldBirthDay = "BirthDay"
+	IllegalPerson_2FieldGender   = "Gender"
+)
+
+// IllegalPerson_2Q typed query builders of IllegalPerson_2 for borm.Where
+var IllegalPerson_2Q = struct {
+	Id       typed.StringField[*IllegalPerson_2]
+	Name     typed.StringField[*IllegalPerson_2]
+	Phone    typed.StringField[*IllegalPerson_2]
+	Age      typed.Field[*IllegalPerson_2, uint32]
+	BirthDay typed.Field[*IllegalPerson_2, uint32]
+	Gender   typed.Field[*IllegalPerson_2, Gender]
+}{
+	Id:       typed.NewStringField[*IllegalPerson_2](IllegalPerson_2FieldId),
+	Name:     typed.NewStringField[*IllegalPerson_2](IllegalPerson_2FieldName),
+	Phone:    typed.NewStringField[*IllegalPerson_2](IllegalPerson_2FieldPhone),
+	Age:      typed.NewField[*IllegalPerson_2, uint32](IllegalPerson_2FieldAge),
+	BirthDay: typed.NewField[*IllegalPerson_2, uint32](IllegalPerson_2FieldBirthDay),
+	Gender:   typed.NewField[*IllegalPerson_2, Gender](IllegalPerson_2FieldGender),
+}
+
+func (*AccountInfo) GetTableName() string {
+	return "AccountInfo"
+}
+
+func (*AccountInfo) Clone() any {
+	return &AccountInfo{}
+}
+
+// field names of AccountInfo
+const (
+	AccountInfoFieldId                = "Id"
+	AccountInfoFieldAccountChannel    = "AccountChannel"
+	AccountInfoFieldAaid              = "Aaid"
+	AccountInfoFieldCashBooks         = "CashBooks"
+	AccountInfoFieldStockBooks        = "StockBooks"
+	AccountInfoFieldAccountProperties = "AccountProperties"
+)
+
+// AccountInfoQ typed query builders of AccountInfo for borm.Where
+var AccountInfoQ = struct {
+	Id             typed.Field[*AccountInfo, uint64]
+	AccountChannel typed.StringField[*AccountInfo]
+	Aaid           typed.Field[*AccountInfo, uint64]
+}{
+	Id:             typed.NewField[*AccountInfo, uint64](AccountInfoFieldId),
+	AccountChannel: typed.NewStringField[*AccountInfo](AccountInfoFieldAccountChannel),
+	Aaid:           typed.NewField[*AccountInfo, uint64](AccountInfoFieldAaid),
+}
+
+func (*Account) GetTableName() string {
+	return "Account"
+}
+
+func (*Account) Clone() any {
+	return &Account{}
+}
+
+// field names of Account
+const (
+	AccountFieldId             = "Id"
+	AccountFieldAccountNo      = "AccountNo"
+	AccountFieldPhoneNumber    = "PhoneNumber"
+	AccountFieldIdentification = "Identification"
+	AccountFieldGender         = "Gender"
+	AccountFieldAge            = "Age"
+	AccountFieldAddress        = "Address"
+)
+
+// AccountQ typed query builders of Account for borm.Where
+var AccountQ = struct {
+	Id             typed.Field[*Account, uint64]
+	AccountNo      typed.StringField[*Account]
+	PhoneNumber    typed.StringField[*Account]
+	Identification typed.StringField[*Account]
+	Gender         typed.Field[*Account, uint32]
+	Age            typed.Field[*Account, uint32]
+	Address        typed.StringField[*Account]
+}{
+	Id:             typed.NewField[*Account, uint64](AccountFieldId),
+	AccountNo:      typed.NewStringField[*Account](AccountFieldAccountNo),
+	PhoneNumber:    typed.NewStringField[*Account](AccountFieldPhoneNumber),
+	Identification: typed.NewStringField[*Account](AccountFieldIdentification),
+	Gender:         typed.NewField[*Account, uint32](AccountFieldGender),
+	Age:            typed.NewField[*Account, uint32](AccountFieldAge),
+	Address:        typed.NewStringField[*Account](AccountFieldAddress),
+}
+
+func (*OrderPot) GetTableName() string {
+	return "OrderPot"
+}
+
+func (*OrderPot) Clone() any {
+	return &OrderPot{}
+}
+
+// field names of OrderPot
+const (
+	OrderPotFieldId             = "Id"
+	OrderPotFieldAccountChannel = "AccountChannel"
+	OrderPotFieldAaid           = "Aaid"
+	OrderPotFieldOrderId        = "OrderId"
+	OrderPotFieldOrgId          = "OrgId"
+	OrderPotFieldCounterId      = "CounterId"
+	OrderPotFieldCurrency       = "Currency"
+	OrderPotFieldMarket         = "Market"
+	OrderPotFieldEntrustType    = "EntrustType"
+	OrderPotFieldEntrustStatus  = "EntrustStatus"
+	OrderPotFieldSide           = "Side"
+	OrderPotFieldEntrustAmount  = "EntrustAmount"
+	OrderPotFieldEntrustQty     = "EntrustQty"
+	OrderPotFieldLiveness       = "Liveness"
+	OrderPotFieldIsAttached     = "IsAttached"
+	OrderPotFieldX3             = "X3"
+	OrderPotFieldX4             = "X4"
+	OrderPotFieldX5             = "X5"
+	OrderPotFieldX11            = "X11"
+	OrderPotFieldX12            = "X12"
+	OrderPotFieldX13            = "X13"
+	OrderPotFieldX14            = "X14"
+	OrderPotFieldX15            = "X15"
+	OrderPotFieldX16            = "X16"
+	OrderPotFieldX17            = "X17"
+	OrderPotFieldX18            = "X18"
+	OrderPotFieldX19            = "X19"
+	OrderPotFieldX20            = "X20"
+	OrderPotFieldX21            = "X21"
+	OrderPotFieldX22            = "X22"
+	OrderPotFieldX23            = "X23"
+	OrderPotFieldX24            = "X24"
+	OrderPotFieldX25            = "X25"
+	OrderPotFieldX26            = "X26"
+	OrderPotFieldX27            = "X27"
+	OrderPotFieldX28            = "X28"
+	OrderPotFieldX29            = "X29"
+	OrderPotFieldX30            = "X30"
+	OrderPotFieldX31            = "X31"
+	OrderPotFieldX32            = "X32"
+	OrderPotFieldX33            = "X33"
+	OrderPotFieldX34            = "X34"
+	OrderPotFieldX35            = "X35"
+	OrderPotFieldX36            = "X36"
+	OrderPotFieldX37            = "X37"
+	OrderPotFieldX38            = "X38"
+	OrderPotFieldX39            = "X39"
+	OrderPotFieldX40            = "X40"
+)
+
+// OrderPotQ typed query builders of OrderPot for borm.Where
+var OrderPotQ = struct {
+	Id             typed.Field[*OrderPot, uint64]
+	AccountChannel typed.StringField[*OrderPot]
+	Aaid           typed.Field[*OrderPot, uint64]
+	OrderId        typed.Field[*OrderPot, int64]
+	OrgId          typed.Field[*OrderPot, int64]
+	CounterId      typed.StringField[*OrderPot]
+	Currency       typed.StringField[*OrderPot]
+	Market         typed.StringField[*OrderPot]
+	EntrustType    typed.Field[*OrderPot, int32]
+	EntrustStatus  typed.Field[*OrderPot, int32]
+	Side           typed.Field[*OrderPot, int32]
+	EntrustAmount  typed.StringField[*OrderPot]
+	EntrustQty     typed.StringField[*OrderPot]
+	Liveness       typed.Field[*OrderPot, int32]
+	IsAttached     typed.Field[*OrderPot, int32]
+	X3             typed.Field[*OrderPot, int32]
+	X4             typed.Field[*OrderPot, int32]
+	X5             typed.Field[*OrderPot, int32]
+	X11            typed.StringField[*OrderPot]
+	X12            typed.StringField[*OrderPot]
+	X13            typed.StringField[*OrderPot]
+	X14            typed.StringField[*OrderPot]
+	X15            typed.StringField[*OrderPot]
+	X16            typed.Field[*OrderPot, int32]
+	X17            typed.Field[*OrderPot, int32]
+	X18            typed.Field[*OrderPot, int32]
+	X19            typed.Field[*OrderPot, int32]
+	X20            typed.Field[*OrderPot, int32]
+	X21            typed.StringField[*OrderPot]
+	X22            typed.StringField[*OrderPot]
+	X23            typed.StringField[*OrderPot]
+	X24            typed.StringField[*OrderPot]
+	X25            typed.StringField[*OrderPot]
+	X26            typed.Field[*OrderPot, int32]
+	X27            typed.Field[*OrderPot, int32]
+	X28            typed.Field[*OrderPot, int32]
+	X29            typed.Field[*OrderPot, int32]
+	X30            typed.Field[*OrderPot, int32]
+	X31            typed.StringField[*OrderPot]
+	X32            typed.StringField[*OrderPot]
+	X33            typed.StringField[*OrderPot]
+	X34            typed.StringField[*OrderPot]
+	X35            typed.StringField[*OrderPot]
+	X36            typed.Field[*OrderPot, int32]
+	X37            typed.Field[*OrderPot, int32]
+	X38            typed.Field[*OrderPot, int32]
+	X39            typed.StringField[*OrderPot]
+	X40            typed.StringField[*OrderPot]
+}{
+	Id:             typed.NewField[*OrderPot, uint64](OrderPotFieldId),
+	AccountChannel: typed.NewStringField[*OrderPot](OrderPotFieldAccountChannel),
+	Aaid:           typed.NewField[*OrderPot, uint64](OrderPotFieldAaid),
+	OrderId:        typed.NewField[*OrderPot, int64](OrderPotFieldOrderId),
+	OrgId:          typed.NewField[*OrderPot, int64](OrderPotFieldOrgId),
+	CounterId:      typed.NewStringField[*OrderPot](OrderPotFieldCounterId),
+	Currency:       typed.NewStringField[*OrderPot](OrderPotFieldCurrency),
+	Market:         typed.NewStringField[*OrderPot](OrderPotFieldMarket),
+	EntrustType:    typed.NewField[*OrderPot, int32](OrderPotFieldEntrustType),
+	EntrustStatus:  typed.NewField[*OrderPot, int32](OrderPotFieldEntrustStatus),
+	Side:           typed.NewField[*OrderPot, int32](OrderPotFieldSide),
+	EntrustAmount:  typed.NewStringField[*OrderPot](OrderPotFieldEntrustAmount),
+	EntrustQty:     typed.NewStringField[*OrderPot](OrderPotFieldEntrustQty),
+	Liveness:       typed.NewField[*OrderPot, int32](OrderPotFieldLiveness),
+	IsAttached:     typed.NewField[*OrderPot, int32](OrderPotFieldIsAttached),
+	X3:             typed.NewField[*OrderPot, int32](OrderPotFieldX3),
+	X4:             typed.NewField[*OrderPot, int32](OrderPotFieldX4),
+	X5:             typed.NewField[*OrderPot, int32](OrderPotFieldX5),
+	X11:            typed.NewStringField[*OrderPot](OrderPotFieldX11),
+	X12:            typed.NewStringField[*OrderPot](OrderPotFieldX12),
+	X13:            typed.NewStringField[*OrderPot](OrderPotFieldX13),
+	X14:            typed.NewStringField[*OrderPot](OrderPotFieldX14),
+	X15:            typed.NewStringField[*OrderPot](OrderPotFieldX15),
+	X16:            typed.NewField[*OrderPot, int32](OrderPotFieldX16),
+	X17:            typed.NewField[*OrderPot, int32](OrderPotFieldX17),
+	X18:            typed.NewField[*OrderPot, int32](OrderPotFieldX18),
+	X19:            typed.NewField[*OrderPot, int32](OrderPotFieldX19),
+	X20:            typed.NewField[*OrderPot, int32](OrderPotFieldX20),
+	X21:            typed.NewStringField[*OrderPot](OrderPotFieldX21),
+	X22:            typed.NewStringField[*OrderPot](OrderPotFieldX22),
+	X23:            typed.NewStringField[*OrderPot](OrderPotFieldX23),
+	X24:            typed.NewStringField[*OrderPot](OrderPotFieldX24),
+	X25:            typed.NewStringField[*OrderPot](OrderPotFieldX25),
+	X26:            typed.NewField[*OrderPot, int32](OrderPotFieldX26),
+	X27:            typed.NewField[*OrderPot, int32](OrderPotFieldX27),
+	X28:            typed.NewField[*OrderPot, int32](OrderPotFieldX28),
+	X29:            typed.NewField[*OrderPot, int32](OrderPotFieldX29),
+	X30:            typed.NewField[*OrderPot, int32](OrderPotFieldX30),
+	X31:            typed.NewStringField[*OrderPot](OrderPotFieldX31),
+	X32:            typed.NewStringField[*OrderPot](OrderPotFieldX32),
+	X33:            typed.NewStringField[*OrderPot](OrderPotFieldX33),
+	X34:            typed.NewStringField[*OrderPot](OrderPotFieldX34),
+	X35:            typed.NewStringField[*OrderPot](OrderPotFieldX35),
+	X36:            typed.NewField[*OrderPot, int32](OrderPotFieldX36),
+	X37:            typed.NewField[*OrderPot, int32](OrderPotFieldX37),
+	X38:            typed.NewField[*OrderPot, int32](OrderPotFieldX38),
+	X39:            typed.NewStringField[*OrderPot](OrderPotFieldX39),
+	X40:            typed.NewStringField[*OrderPot](OrderPotFieldX40),
+}
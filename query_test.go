@@ -1,10 +1,16 @@
 package borm
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"math"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/dgraph-io/badger/v3"
+	"github.com/gogo/protobuf/types"
 	"github.com/longbridgeapp/borm/pb"
 	"github.com/stretchr/testify/require"
 )
@@ -793,3 +799,504 @@ func TestIn(t *testing.T) {
 		})
 	})
 }
+
+type position struct {
+	Id             uint64
+	AccountChannel string `idx:"union=acct_order;union=acct,multi"`
+	Aaid           uint64 `idx:"union=acct_order;union=acct,multi;union=pos,multi"`
+	OrderId        string `idx:"union=acct_order"`
+	CounterId      string `idx:"union=pos,multi"`
+	Qty            uint64
+}
+
+func (p *position) Marshal() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+func (p *position) Unmarshal(bs []byte) error {
+	return json.Unmarshal(bs, p)
+}
+
+func (*position) GetTableName() string {
+	return "position"
+}
+
+func (*position) Clone() any {
+	return &position{}
+}
+
+type illegalUnion struct {
+	Id      uint64
+	Aaid    uint64 `idx:"union=acct"`
+	OrderId string `idx:"union=acct,multi"`
+}
+
+func (p *illegalUnion) Marshal() ([]byte, error) {
+	return json.Marshal(p)
+}
+
+func (p *illegalUnion) Unmarshal(bs []byte) error {
+	return json.Unmarshal(bs, p)
+}
+
+func (*illegalUnion) GetTableName() string {
+	return "illegalUnion"
+}
+
+func (*illegalUnion) Clone() any {
+	return &illegalUnion{}
+}
+
+func TestNamedUnionIndex(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&illegalUnion{})
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+		err = db.CreateTable(&position{})
+		require.NoError(t, err)
+
+		for i := 0; i < 10; i++ {
+			err = db.Insert(&position{
+				AccountChannel: "lb",
+				Aaid:           uint64(10000 + i%2),
+				OrderId:        fmt.Sprint(i),
+				CounterId:      fmt.Sprintf("ST/HK/%d", i%3),
+				Qty:            uint64(i),
+			})
+			require.NoError(t, err)
+		}
+		err = db.Insert(&position{AccountChannel: "lb", Aaid: 10000, OrderId: "0"})
+		require.ErrorIs(t, err, ErrIdxUniqueConflict)
+
+		tables, err := db.Tables()
+		require.NoError(t, err)
+		require.Equal(t, []IndexInfo{
+			{Name: "acct_order", Type: UNION, Fields: []string{"AccountChannel", "Aaid", "OrderId"}, Unique: true},
+			{Name: "acct", Type: UNION, Fields: []string{"AccountChannel", "Aaid"}},
+			{Name: "pos", Type: UNION, Fields: []string{"Aaid", "CounterId"}},
+		}, tables[0].Indexes[4:])
+
+		detail, err := db.Snoop(&position{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), detail.UnionIndex["acct_order"])
+		require.Equal(t, uint64(10), detail.UnionIndex["acct"])
+		require.Equal(t, uint64(10), detail.UnionIndex["pos"])
+		require.Equal(t, uint64(30), detail.UnionIndexCount)
+
+		result, err := First(db, WithAnd(&position{}).Eq("OrderId", "3").Eq("Aaid", uint64(10001)).Eq("AccountChannel", "lb"))
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), result.Qty)
+
+		results, err := Find(db, WithAnd(&position{}).Eq("AccountChannel", "lb").Eq("Aaid", uint64(10000)))
+		require.NoError(t, err)
+		require.Equal(t, 5, len(results))
+
+		results, err = Find(db, WithAnd(&position{}).Eq("Aaid", uint64(10000)).Eq("CounterId", "ST/HK/0"))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(results))
+		require.Equal(t, uint64(0), results[0].Qty)
+		require.Equal(t, uint64(6), results[1].Qty)
+
+		err = db.Update(results[0].Id, &position{AccountChannel: "lb", Aaid: 10000, OrderId: "0", CounterId: "ST/HK/1"})
+		require.NoError(t, err)
+		err = db.Delete(results[1].Id, &position{})
+		require.NoError(t, err)
+		count, err := Count(db, WithAnd(&position{}).Eq("Aaid", uint64(10000)).Eq("CounterId", "ST/HK/0"))
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+		count, err = Count(db, WithAnd(&position{}).Eq("Aaid", uint64(10000)).Eq("CounterId", "ST/HK/1"))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		detail, err = db.Snoop(&position{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(27), detail.UnionIndexCount)
+
+		err = db.View(func(txn *badger.Txn) error {
+			ids, err := db.TxQueryWithMultiUnionIndexWithFieldMap(txn, &position{}, map[string]any{"Aaid": uint64(10000), "CounterId": "ST/HK/1"})
+			require.NoError(t, err)
+			require.Equal(t, 2, len(ids))
+			require.Less(t, ids[0], ids[1])
+			_, err = db.TxQueryWithUnionIndexWithFieldMap(txn, &position{}, map[string]any{"Aaid": uint64(10000), "CounterId": "ST/HK/1"})
+			require.ErrorIs(t, err, ErrIdxNotSupport)
+			id, err := db.TxQueryWithUnionIndexWithFieldMap(txn, &position{}, map[string]any{"AccountChannel": "lb", "Aaid": uint64(10001), "OrderId": "3"})
+			require.NoError(t, err)
+			_, err = db.TxQueryWithMultiUnionIndexWithFieldMap(txn, &position{}, map[string]any{"AccountChannel": "lb", "Aaid": uint64(10001), "OrderId": "3"})
+			require.ErrorIs(t, err, ErrIdxNotSupport)
+			require.NotZero(t, id)
+			return nil
+		})
+		require.NoError(t, err)
+	})
+}
+
+func TestUnionLeftPrefix(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Order{})
+		require.NoError(t, err)
+		for i := 0; i < 12; i++ {
+			err = db.Insert(&pb.Order{
+				AccountChannel: []string{"lb", "lb:1", "lb\x00"}[i%3],
+				Aaid:           uint64(1 + i%2),
+				OrderId:        fmt.Sprint(i),
+				CounterId:      fmt.Sprintf("ST/HK/%d", i%4),
+			})
+			require.NoError(t, err)
+		}
+
+		count, err := Count(db, WithAnd(&pb.Order{}).Eq("AccountChannel", "lb").Eq("Aaid", 1))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		count, err = Count(db, WithAnd(&pb.Order{}).Eq("AccountChannel", "lb:1").Eq("Aaid", uint64(2)))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		results, err := Find(db, WithAnd(&pb.Order{}).Eq("Aaid", uint64(2)).Eq("AccountChannel", "lb\x00").Eq("CounterId", "ST/HK/1"))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(results))
+		require.Equal(t, "5", results[0].OrderId)
+
+		//the full union is still a point lookup
+		result, err := First(db, WithAnd(&pb.Order{}).Eq("AccountChannel", "lb").Eq("Aaid", uint64(1)).Eq("OrderId", "6"))
+		require.NoError(t, err)
+		require.Equal(t, "ST/HK/2", result.CounterId)
+
+		_, err = Find(db, WithAnd(&pb.Order{}).Eq("AccountChannel", "lb").Eq("Aaid", "1"))
+		require.ErrorIs(t, err, ErrQueryInvalid)
+	})
+}
+
+func TestEncodeOrderedValue(t *testing.T) {
+	cases := []struct {
+		fieldType FieldType
+		vals      []any
+	}{
+		{Int64, []any{int64(-1 << 40), -2, int64(-1), 0, int64(3), int64(1 << 40)}},
+		{Uint32, []any{uint32(0), uint32(2), 300, uint32(1 << 31)}},
+		{Float64, []any{-1e10, -1.5, -0.0, 0.5, 2, 1e10}},
+		{String, []any{"", "\x00", "\x00\x00", "a", "a\x00", "a:", "ab", "b"}},
+		{Bytes, []any{[]byte{}, []byte{0x00}, []byte{0x00, 0x01}, []byte{0x01}}},
+		{Bool, []any{false, true}},
+		{Int32, []any{pb.Gender(-1), pb.Gender_men, pb.Gender_women}},
+		{Time, []any{time.Time{}, time.Unix(-1, 0), time.Unix(0, 0), time.Unix(0, 1), time.Unix(1, 0)}},
+	}
+	for _, c := range cases {
+		for i := 1; i < len(c.vals); i++ {
+			prev, err := encodeOrderedValue(nil, c.fieldType, c.vals[i-1])
+			require.NoError(t, err)
+			cur, err := encodeOrderedValue(nil, c.fieldType, c.vals[i])
+			require.NoError(t, err)
+			require.Equal(t, -1, bytes.Compare(prev, cur), "%v < %v", c.vals[i-1], c.vals[i])
+			require.False(t, bytes.HasPrefix(cur, prev))
+		}
+	}
+}
+
+type typedRow struct {
+	Id        uint64
+	Active    bool             `idx:"normal"`
+	Gender    pb.Gender        `idx:"normal;union=gender_created,multi"`
+	Digest    []byte           `idx:"unique"`
+	CreatedAt time.Time        `idx:"union=gender_created,multi"`
+	SettledAt *types.Timestamp `idx:"normal"`
+	Count     int              `idx:"normal"`
+	Level     int8             `idx:"normal"`
+	Size      uint             `idx:"normal"`
+}
+
+func (r *typedRow) Marshal() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+func (r *typedRow) Unmarshal(bs []byte) error {
+	return json.Unmarshal(bs, r)
+}
+
+func (*typedRow) GetTableName() string {
+	return "typedRow"
+}
+
+func (*typedRow) Clone() any {
+	return &typedRow{}
+}
+
+func TestTypedIndex(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&typedRow{})
+		require.NoError(t, err)
+		base := time.Date(2022, 1, 1, 8, 0, 0, 0, time.FixedZone("HKT", 8*3600))
+		for i := 0; i < 6; i++ {
+			row := &typedRow{
+				Active:    i%2 == 0,
+				Gender:    pb.Gender(i % 2),
+				Digest:    []byte{0x00, byte(i)},
+				CreatedAt: base.Add(time.Duration(i/2) * time.Hour),
+				Count:     20 + i,
+				Level:     int8(40 + i),
+				Size:      math.MaxUint - uint(i),
+			}
+			if i > 2 {
+				row.SettledAt = &types.Timestamp{Seconds: base.Unix(), Nanos: int32(i % 2)}
+			}
+			err = db.Insert(row)
+			require.NoError(t, err)
+		}
+		err = db.Insert(&typedRow{Digest: []byte{0x00, 0x01}})
+		require.ErrorIs(t, err, ErrIdxUniqueConflict)
+
+		count, err := Count(db, WithAnd(&typedRow{}).Eq("Active", true))
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		count, err = Count(db, WithAnd(&typedRow{}).Eq("Gender", pb.Gender_women))
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		count, err = Count(db, WithAnd(&typedRow{}).Eq("Gender", 1).Eq("Active", false))
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		result, err := First(db, WithAnd(&typedRow{}).Eq("Digest", []byte{0x00, 0x04}))
+		require.NoError(t, err)
+		require.Equal(t, pb.Gender_men, result.Gender)
+		results, err := Find(db, WithAnd(&typedRow{}).Eq("SettledAt", base.UTC()).SortBy(true, "Digest"))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(results))
+		require.Equal(t, []byte{0x00, 0x04}, results[0].Digest)
+		count, err = Count(db, WithAnd(&typedRow{}).Eq("SettledAt", &types.Timestamp{Seconds: base.Unix(), Nanos: 1}))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		//the union matches the time in any zone
+		results, err = Find(db, WithAnd(&typedRow{}).Eq("Gender", pb.Gender_women).Eq("CreatedAt", base.Add(time.Hour).UTC()))
+		require.NoError(t, err)
+		require.Equal(t, 1, len(results))
+		require.Equal(t, []byte{0x00, 0x03}, results[0].Digest)
+
+		_, err = Find(db, WithAnd(&typedRow{}).Eq("Active", "true"))
+		require.ErrorIs(t, err, ErrQueryInvalid)
+
+		//numbers convert to the field type only when the value is kept
+		count, err = Count(db, WithAnd(&typedRow{}).Eq("Count", 21.0))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		count, err = Count(db, WithAnd(&typedRow{}).Eq("Level", int64(44)))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		_, err = Count(db, WithAnd(&typedRow{}).Eq("Count", 21.7))
+		require.ErrorIs(t, err, ErrQueryInvalid)
+		_, err = Count(db, WithAnd(&typedRow{}).Eq("Size", -1))
+		require.ErrorIs(t, err, ErrQueryInvalid)
+		_, err = Count(db, WithAnd(&typedRow{}).Eq("Level", 300))
+		require.ErrorIs(t, err, ErrQueryInvalid)
+	})
+}
+
+func TestPartialIndex(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Order{}, WithPartialIndex("CounterId", "CounterId", NORMAL, "EntrustStatus", 1))
+		require.ErrorIs(t, err, ErrIdxRepeat)
+		err = db.CreateTable(&pb.Order{}, WithPartialIndex("open_counter", "CounterId", NORMAL, "EntrustStatus", "open"))
+		require.ErrorIs(t, err, ErrQueryInvalid)
+		err = db.CreateTable(&pb.Order{}, WithPartialIndex("open_counter", "CounterId", NORMAL, "EntrustStatus", 1, 2))
+		require.NoError(t, err)
+		for i := 0; i < 20; i++ {
+			err = db.Insert(&pb.Order{
+				AccountChannel: "lb",
+				Aaid:           100,
+				OrderId:        fmt.Sprint(i),
+				CounterId:      fmt.Sprintf("ST/HK/%d", i%2),
+				EntrustStatus:  int32(i % 4),
+			})
+			require.NoError(t, err)
+		}
+		detail, err := db.Snoop(&pb.Order{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(20), detail.NormalIndex["CounterId"])
+		require.Equal(t, uint64(10), detail.NormalIndex["open_counter"])
+		tables, err := db.Tables()
+		require.NoError(t, err)
+		require.Contains(t, tables[0].Indexes, IndexInfo{Name: "open_counter", Type: NORMAL, Fields: []string{"CounterId"}, Partial: true})
+
+		results, err := Find(db, WithAnd(&pb.Order{}).Eq("CounterId", "ST/HK/1").Eq("EntrustStatus", int32(1)))
+		require.NoError(t, err)
+		require.Equal(t, 5, len(results))
+		count, err := Count(db, WithAnd(&pb.Order{}).Eq("CounterId", "ST/HK/0").Eq("EntrustStatus", 2))
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+		count, err = Count(db, WithAnd(&pb.Order{}).Eq("CounterId", "ST/HK/0").Eq("EntrustStatus", 1))
+		require.NoError(t, err)
+		require.Equal(t, 0, count)
+		count, err = Count(db, WithAnd(&pb.Order{}).Eq("CounterId", "ST/HK/0"))
+		require.NoError(t, err)
+		require.Equal(t, 10, count)
+		//the predicate is not implied, EntrustStatus has no index of its own
+		_, err = Count(db, WithAnd(&pb.Order{}).Eq("CounterId", "ST/HK/0").Eq("EntrustStatus", 0))
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+
+		order := results[0]
+		order.EntrustStatus = 3
+		err = db.Update(order.Id, order)
+		require.NoError(t, err)
+		count, err = Count(db, WithAnd(&pb.Order{}).Eq("CounterId", "ST/HK/1").Eq("EntrustStatus", 1))
+		require.NoError(t, err)
+		require.Equal(t, 4, count)
+		err = db.Delete(results[1].Id, &pb.Order{})
+		require.NoError(t, err)
+		detail, err = db.Snoop(&pb.Order{})
+		require.NoError(t, err)
+		require.Equal(t, uint64(8), detail.NormalIndex["open_counter"])
+
+		err = db.DropIndex(&pb.Order{}, "open_counter")
+		require.NoError(t, err)
+		_, err = Count(db, WithAnd(&pb.Order{}).Eq("CounterId", "ST/HK/1").Eq("EntrustStatus", 1))
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+	})
+}
+
+type ciAccount struct {
+	Id      uint64
+	Name    string `idx:"normal,ci"`
+	Email   string `idx:"unique,ci"`
+	Channel string `idx:"normal,ci;union=channel_aaid,multi"`
+	Aaid    uint64 `idx:"union=channel_aaid,multi"`
+}
+
+func (a *ciAccount) Marshal() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+func (a *ciAccount) Unmarshal(bs []byte) error {
+	return json.Unmarshal(bs, a)
+}
+
+func (*ciAccount) GetTableName() string {
+	return "ciAccount"
+}
+
+func (*ciAccount) Clone() any {
+	return &ciAccount{}
+}
+
+type illegalCiAccount struct {
+	Id   uint64
+	Aaid uint64 `idx:"normal,ci"`
+}
+
+func (a *illegalCiAccount) Marshal() ([]byte, error) {
+	return json.Marshal(a)
+}
+
+func (a *illegalCiAccount) Unmarshal(bs []byte) error {
+	return json.Unmarshal(bs, a)
+}
+
+func (*illegalCiAccount) GetTableName() string {
+	return "illegalCiAccount"
+}
+
+func (*illegalCiAccount) Clone() any {
+	return &illegalCiAccount{}
+}
+
+func TestCaseInsensitiveIndex(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&illegalCiAccount{})
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+		err = db.CreateTable(&ciAccount{})
+		require.NoError(t, err)
+
+		err = db.Insert(&ciAccount{Name: "Jacky Chan", Email: "Jacky@LB.com", Channel: "LB", Aaid: 1})
+		require.NoError(t, err)
+		err = db.Insert(&ciAccount{Name: " jacky chan ", Email: "jacky2@lb.com", Channel: "ｌｂ", Aaid: 1})
+		require.NoError(t, err)
+		err = db.Insert(&ciAccount{Name: "Jim", Email: " JACKY@lb.com", Channel: "lb", Aaid: 2})
+		require.ErrorIs(t, err, ErrIdxUniqueConflict)
+
+		results, err := Find(db, WithAnd(&ciAccount{}).Eq("Name", "JACKY CHAN").SortBy(false, "Email"))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(results))
+		//sorted by the stored values, not the folded ones
+		require.Equal(t, "Jacky Chan", results[0].Name)
+		require.Equal(t, " jacky chan ", results[1].Name)
+		name, err := db.GetFieldValWithFieldName(results[1], "Name")
+		require.NoError(t, err)
+		require.Equal(t, " jacky chan ", name)
+		email, err := db.GetFieldValWithFieldName(results[0], "Email")
+		require.NoError(t, err)
+		require.Equal(t, "Jacky@LB.com", email)
+		result, err := First(db, WithAnd(&ciAccount{}).Eq("Email", "jacky@lb.COM"))
+		require.NoError(t, err)
+		require.Equal(t, "Jacky@LB.com", result.Email)
+		count, err := Count(db, WithAnd(&ciAccount{}).In([]string{"Email"}, [][]any{{"JACKY@LB.COM"}, {"Jacky2@Lb.Com"}}))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		count, err = Count(db, WithAnd(&ciAccount{}).Eq("Channel", "Lb").Eq("Aaid", uint64(1)))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+
+		//the entries of the old folded value are removed on update
+		err = db.Update(results[1].Id, &ciAccount{Name: "JIM", Email: "jacky2@lb.com", Channel: "LB", Aaid: 1})
+		require.NoError(t, err)
+		count, err = Count(db, WithAnd(&ciAccount{}).Eq("Name", "jacky chan"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		count, err = Count(db, WithAnd(&ciAccount{}).Eq("Name", "jim"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	})
+}
+
+func TestStartsWith(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.CreateTable(&ciAccount{})
+		require.NoError(t, err)
+		phones := []string{"+8613800", "+8613811", "+8615900", "+85291", "+1650"}
+		for i, phone := range phones {
+			err = db.Insert(&pb.Person{Name: fmt.Sprintf("ab:%d", i%3), Phone: phone, Age: uint32(20 + i%2)})
+			require.NoError(t, err)
+		}
+		err = db.Insert(&pb.Person{Name: "ab", Phone: "+0", Age: 20})
+		require.NoError(t, err)
+
+		results, err := Find(db, WithAnd(&pb.Person{}).StartsWith("Phone", "+86138"))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(results))
+		count, err := Count(db, WithAnd(&pb.Person{}).Like("Phone", "+86%"))
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		count, err = Count(db, WithAnd(&pb.Person{}).Like("Phone", "+85291"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		count, err = Count(db, WithAnd(&pb.Person{}).StartsWith("Phone", "+86").Eq("Age", uint32(21)))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		//the row id of "ab" must not match the prefix "ab:"
+		count, err = Count(db, WithAnd(&pb.Person{}).StartsWith("Name", "ab:"))
+		require.NoError(t, err)
+		require.Equal(t, 5, count)
+		count, err = Count(db, WithAnd(&pb.Person{}).StartsWith("Name", "ab:1").StartsWith("Phone", "+8"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		_, err = Find(db, WithAnd(&pb.Person{}).Like("Phone", "+86%00"))
+		require.ErrorIs(t, err, ErrQueryInvalid)
+		_, err = Find(db, WithAnd(&pb.Person{}).StartsWith("Age", "2"))
+		require.ErrorIs(t, err, ErrIdxNotSupport)
+
+		err = db.Insert(&ciAccount{Name: "Jacky Chan", Email: "Jacky@LB.com"})
+		require.NoError(t, err)
+		count, err = Count(db, WithAnd(&ciAccount{}).StartsWith("Name", " JACKY C"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+		count, err = Count(db, WithAnd(&ciAccount{}).Like("Email", "JACKY@%"))
+		require.NoError(t, err)
+		require.Equal(t, 1, count)
+	})
+}
+
+//recordLogger
+//keeps the printed query analyzer lines
+type recordLogger struct {
+	Logger
+	lines []string
+}
+
+func (l *recordLogger) Printf(f string, v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprintf(f, v...))
+}
@@ -0,0 +1,144 @@
+package borm
+
+import (
+	"sync"
+	"time"
+
+	badger "github.com/dgraph-io/badger/v3"
+)
+
+//Snapshot
+//a point-in-time read view backed by a badger read timestamp,
+//every query function accepts Snapshot.Txn() and sees the data as of ReadTs()
+type Snapshot struct {
+	db    *BormDb
+	entry *snapshotEntry
+	once  sync.Once
+}
+
+type snapshotEntry struct {
+	txn        *badger.Txn
+	refs       int
+	releasedAt time.Time
+}
+
+type snapshotOptions struct {
+	readTs uint64
+}
+
+type SnapshotOption func(*snapshotOptions)
+
+//ReadAt
+//reopen the snapshot held at read timestamp ts, the timestamp must belong to a
+//snapshot that is still open or released within the snapshot retention
+func ReadAt(ts uint64) SnapshotOption {
+	return func(o *snapshotOptions) {
+		o.readTs = ts
+	}
+}
+
+type snapshotManager struct {
+	sync.Mutex
+	retention time.Duration
+	entries   map[uint64]*snapshotEntry
+}
+
+func newSnapshotManager(retention time.Duration) *snapshotManager {
+	return &snapshotManager{
+		retention: retention,
+		entries:   map[uint64]*snapshotEntry{},
+	}
+}
+
+//Snapshot
+//hold a consistent read view, Release must be called when it is no longer used
+func (bormDb *BormDb) Snapshot(opts ...SnapshotOption) (*Snapshot, error) {
+	o := &snapshotOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	m := bormDb.snapshots
+	m.Lock()
+	defer m.Unlock()
+	m.evict(time.Now())
+
+	if o.readTs > 0 {
+		entry, ok := m.entries[o.readTs]
+		if !ok {
+			return nil, ErrSnapshotNotFound
+		}
+		entry.refs++
+		return &Snapshot{db: bormDb, entry: entry}, nil
+	}
+	txn := bormDb.db.NewTransaction(false)
+	entry, ok := m.entries[txn.ReadTs()]
+	if ok {
+		//no commit since the retained snapshot, share its read view
+		txn.Discard()
+	} else {
+		entry = &snapshotEntry{txn: txn}
+		m.entries[txn.ReadTs()] = entry
+	}
+	entry.refs++
+	return &Snapshot{db: bormDb, entry: entry}, nil
+}
+
+func (s *Snapshot) ReadTs() uint64 {
+	return s.entry.txn.ReadTs()
+}
+
+func (s *Snapshot) Txn() *badger.Txn {
+	return s.entry.txn
+}
+
+func (s *Snapshot) View(fn func(txn *badger.Txn) error) error {
+	return fn(s.entry.txn)
+}
+
+//Release
+//the read view is kept for the snapshot retention after the last release
+func (s *Snapshot) Release() {
+	s.once.Do(func() {
+		m := s.db.snapshots
+		m.Lock()
+		defer m.Unlock()
+		s.entry.refs--
+		now := time.Now()
+		if s.entry.refs == 0 {
+			s.entry.releasedAt = now
+			if m.retention > 0 {
+				//evict without waiting for another Snapshot or Release call
+				time.AfterFunc(m.retention, m.evictNow)
+			}
+		}
+		m.evict(now)
+	})
+}
+
+func (m *snapshotManager) evictNow() {
+	m.Lock()
+	defer m.Unlock()
+	m.evict(time.Now())
+}
+
+func (m *snapshotManager) evict(now time.Time) {
+	for ts, entry := range m.entries {
+		if entry.refs > 0 {
+			continue
+		}
+		if now.Sub(entry.releasedAt) < m.retention {
+			continue
+		}
+		entry.txn.Discard()
+		delete(m.entries, ts)
+	}
+}
+
+func (m *snapshotManager) close() {
+	m.Lock()
+	defer m.Unlock()
+	for ts, entry := range m.entries {
+		entry.txn.Discard()
+		delete(m.entries, ts)
+	}
+}
@@ -0,0 +1,126 @@
+package borm
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/longbridgeapp/borm/pb"
+
+	"github.com/dgraph-io/badger/v3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	t.Run("point in time read", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			err := db.CreateTable(&pb.Person{})
+			require.NoError(t, err)
+			err = db.CreateTable(&pb.Order{})
+			require.NoError(t, err)
+			for i := 0; i < 10; i++ {
+				err = db.Insert(&pb.Person{
+					Name:  "jacky",
+					Phone: fmt.Sprintf("+86%d", i),
+					Age:   30,
+				})
+				require.NoError(t, err)
+			}
+
+			snapshot, err := db.Snapshot()
+			require.NoError(t, err)
+			defer snapshot.Release()
+
+			for i := 10; i < 20; i++ {
+				err = db.Insert(&pb.Person{
+					Name:  "jacky",
+					Phone: fmt.Sprintf("+86%d", i),
+					Age:   30,
+				})
+				require.NoError(t, err)
+			}
+			err = db.Insert(&pb.Order{AccountChannel: "lb", Aaid: 1, OrderId: "1"})
+			require.NoError(t, err)
+			err = db.Update(1, &pb.Person{Name: "jim", Phone: "+860", Age: 31})
+			require.NoError(t, err)
+
+			persons, err := TxFind(snapshot.Txn(), db, WithAnd(&pb.Person{}).Eq("Name", "jacky"))
+			require.NoError(t, err)
+			require.Equal(t, 10, len(persons))
+
+			count, err := db.TxCount(snapshot.Txn(), &pb.Order{})
+			require.NoError(t, err)
+			require.Equal(t, uint64(0), count)
+
+			persons, err = Find(db, WithAnd(&pb.Person{}).Eq("Name", "jacky"))
+			require.NoError(t, err)
+			require.Equal(t, 19, len(persons))
+		})
+	})
+
+	t.Run("read at", func(t *testing.T) {
+		db, err := New(WithSnapshotRetention(time.Minute))
+		require.NoError(t, err)
+		defer db.Close()
+		err = db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.Insert(&pb.Person{Name: "jacky", Phone: "+860", Age: 30})
+		require.NoError(t, err)
+
+		snapshot, err := db.Snapshot()
+		require.NoError(t, err)
+		readTs := snapshot.ReadTs()
+		snapshot.Release()
+
+		err = db.Insert(&pb.Person{Name: "jacky", Phone: "+861", Age: 30})
+		require.NoError(t, err)
+
+		snapshot, err = db.Snapshot(ReadAt(readTs))
+		require.NoError(t, err)
+		defer snapshot.Release()
+		require.Equal(t, readTs, snapshot.ReadTs())
+		err = snapshot.View(func(txn *badger.Txn) error {
+			count, err := TxCount(txn, db, WithAnd(&pb.Person{}).Eq("Name", "jacky"))
+			require.NoError(t, err)
+			require.Equal(t, 1, count)
+			return nil
+		})
+		require.NoError(t, err)
+
+		_, err = db.Snapshot(ReadAt(readTs + 100))
+		require.ErrorIs(t, err, ErrSnapshotNotFound)
+	})
+
+	t.Run("evicted after retention", func(t *testing.T) {
+		db, err := New(WithSnapshotRetention(200 * time.Millisecond))
+		require.NoError(t, err)
+		defer db.Close()
+		snapshot, err := db.Snapshot()
+		require.NoError(t, err)
+		snapshot.Release()
+		db.snapshots.Lock()
+		require.Len(t, db.snapshots.entries, 1)
+		db.snapshots.Unlock()
+
+		require.Eventually(t, func() bool {
+			db.snapshots.Lock()
+			defer db.snapshots.Unlock()
+			return len(db.snapshots.entries) == 0
+		}, 2*time.Second, 20*time.Millisecond)
+	})
+
+	t.Run("released without retention", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			err := db.CreateTable(&pb.Person{})
+			require.NoError(t, err)
+			snapshot, err := db.Snapshot()
+			require.NoError(t, err)
+			readTs := snapshot.ReadTs()
+			snapshot.Release()
+			snapshot.Release()
+
+			_, err = db.Snapshot(ReadAt(readTs))
+			require.ErrorIs(t, err, ErrSnapshotNotFound)
+		})
+	})
+}
@@ -0,0 +1,67 @@
+package borm
+
+import (
+	"reflect"
+)
+
+//StructRow
+//adapts a plain struct T to IRow, the table is named by WithTableName or else by the
+//type name of T, T is encoded by ReflectCodec unless the table sets WithCodec, the first
+//field of T must be `Id uint64` and the indexes, checks, hooks and queries use the fields
+//and methods of T, e.g. db.Insert(&StructRow[Quote]{Row: Quote{Symbol: "700.HK"}})
+type StructRow[T any] struct {
+	Row T
+}
+
+func (s *StructRow[T]) Marshal() ([]byte, error) {
+	return ReflectCodec{}.Marshal(&s.Row)
+}
+
+func (s *StructRow[T]) Unmarshal(data []byte) error {
+	return ReflectCodec{}.Unmarshal(data, &s.Row)
+}
+
+func (s *StructRow[T]) GetTableName() string {
+	return reflect.TypeOf((*T)(nil)).Elem().Name()
+}
+
+func (s *StructRow[T]) Clone() any {
+	return &StructRow[T]{}
+}
+
+func (s *StructRow[T]) structPtr() any {
+	return &s.Row
+}
+
+//structRow
+//a StructRow of any T
+type structRow interface {
+	IRow
+	structPtr() any
+}
+
+var structRowType = reflect.TypeOf((*structRow)(nil)).Elem()
+
+//rowTarget
+//the value holding the fields and hooks of row, the struct pointer wrapped by a StructRow
+func rowTarget(row any) any {
+	if s, ok := row.(structRow); ok {
+		return s.structPtr()
+	}
+	return row
+}
+
+//rowStruct
+//the struct holding the fields of row
+func rowStruct(row any) reflect.Value {
+	return reflect.ValueOf(rowTarget(row)).Elem()
+}
+
+//rowStructType
+//the struct type holding the fields of the rows of the pointer type rowType
+func rowStructType(rowType reflect.Type) reflect.Type {
+	if rowType.Implements(structRowType) {
+		return rowType.Elem().Field(0).Type
+	}
+	return rowType.Elem()
+}
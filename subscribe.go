@@ -0,0 +1,246 @@
+package borm
+
+import (
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	badger "github.com/dgraph-io/badger/v3"
+)
+
+type ChangeType int
+
+const (
+	ChangeInsert ChangeType = iota + 1
+	ChangeUpdate
+	ChangeDelete
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case ChangeInsert:
+		return "INSERT"
+	case ChangeUpdate:
+		return "UPDATE"
+	case ChangeDelete:
+		return "DELETE"
+	}
+	return "UNKNOWN"
+}
+
+//ChangeEvent
+//Before is nil for insert, After is nil for delete,
+//the images are shared by all subscribers and must not be modified
+type ChangeEvent struct {
+	Type      ChangeType
+	TableName string
+	RowId     uint64
+	Before    IRow
+	After     IRow
+}
+
+type ChangeFilter func(event *ChangeEvent) bool
+
+type ChangeHandler func(event *ChangeEvent)
+
+type Subscription struct {
+	//first for 64-bit atomic alignment
+	dropped uint64
+	id      uint64
+	tableId uint32
+	hub     *changeHub
+	filter  ChangeFilter
+	handler ChangeHandler
+	events  chan *ChangeEvent
+	done    chan struct{}
+	once    sync.Once
+}
+
+//Close
+//stop receiving events, events already queued are dropped
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		close(s.done)
+		s.hub.remove(s)
+	})
+}
+
+func (s *Subscription) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case event := <-s.events:
+			s.handler(event)
+		}
+	}
+}
+
+//Dropped
+//the number of events dropped because the buffer of the subscription was full
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+//deliver
+//never blocks the committing txn, a full buffer drops the event
+func (s *Subscription) deliver(event *ChangeEvent) {
+	if s.filter != nil && !s.filter(event) {
+		return
+	}
+	select {
+	case s.events <- event:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+type txnChanges struct {
+	events   []*ChangeEvent
+	tableIds []uint32
+}
+
+type changeHub struct {
+	sync.RWMutex
+	nextId uint64
+	subs   map[uint32]map[uint64]*Subscription
+	//txnKey of the writing txn to its *txnChanges
+	pending sync.Map
+	//keep publishing in commit order
+	publishLock sync.Mutex
+	bufferSize  int
+}
+
+func newChangeHub(bufferSize int) *changeHub {
+	return &changeHub{
+		subs:       map[uint32]map[uint64]*Subscription{},
+		bufferSize: bufferSize,
+	}
+}
+
+func (h *changeHub) add(tableId uint32, filter ChangeFilter, handler ChangeHandler) *Subscription {
+	h.Lock()
+	defer h.Unlock()
+	h.nextId++
+	s := &Subscription{
+		id:      h.nextId,
+		tableId: tableId,
+		hub:     h,
+		filter:  filter,
+		handler: handler,
+		events:  make(chan *ChangeEvent, h.bufferSize),
+		done:    make(chan struct{}),
+	}
+	if _, ok := h.subs[tableId]; !ok {
+		h.subs[tableId] = map[uint64]*Subscription{}
+	}
+	h.subs[tableId][s.id] = s
+	go s.run()
+	return s
+}
+
+func (h *changeHub) remove(s *Subscription) {
+	h.Lock()
+	defer h.Unlock()
+	delete(h.subs[s.tableId], s.id)
+	if len(h.subs[s.tableId]) == 0 {
+		delete(h.subs, s.tableId)
+	}
+}
+
+func (h *changeHub) watching(tableId uint32) bool {
+	h.RLock()
+	defer h.RUnlock()
+	return len(h.subs[tableId]) > 0
+}
+
+//txnKey
+//the address of txn, pending changes must not keep the txn reachable
+func txnKey(txn *badger.Txn) uintptr {
+	return reflect.ValueOf(txn).Pointer()
+}
+
+func (h *changeHub) record(txn *badger.Txn, tableId uint32, event *ChangeEvent) {
+	key := txnKey(txn)
+	v, loaded := h.pending.LoadOrStore(key, &txnChanges{})
+	if !loaded {
+		//a txn committed or discarded by itself drops its changes once it is unreachable
+		runtime.SetFinalizer(txn, func(*badger.Txn) {
+			h.pending.Delete(key)
+		})
+	}
+	changes := v.(*txnChanges)
+	changes.events = append(changes.events, event)
+	changes.tableIds = append(changes.tableIds, tableId)
+}
+
+func (h *changeHub) discard(txn *badger.Txn) {
+	if _, ok := h.pending.LoadAndDelete(txnKey(txn)); ok {
+		runtime.SetFinalizer(txn, nil)
+	}
+}
+
+func (h *changeHub) commit(txn *badger.Txn) error {
+	v, ok := h.pending.LoadAndDelete(txnKey(txn))
+	if !ok {
+		return txn.Commit()
+	}
+	runtime.SetFinalizer(txn, nil)
+	changes := v.(*txnChanges)
+	h.publishLock.Lock()
+	defer h.publishLock.Unlock()
+	if err := txn.Commit(); err != nil {
+		return err
+	}
+	h.RLock()
+	defer h.RUnlock()
+	for i, event := range changes.events {
+		for _, s := range h.subs[changes.tableIds[i]] {
+			s.deliver(event)
+		}
+	}
+	return nil
+}
+
+func (h *changeHub) removeTable(tableId uint32) {
+	h.RLock()
+	subs := []*Subscription{}
+	for _, s := range h.subs[tableId] {
+		subs = append(subs, s)
+	}
+	h.RUnlock()
+	for _, s := range subs {
+		s.Close()
+	}
+}
+
+func (h *changeHub) close() {
+	h.RLock()
+	subs := []*Subscription{}
+	for _, tableSubs := range h.subs {
+		for _, s := range tableSubs {
+			subs = append(subs, s)
+		}
+	}
+	h.RUnlock()
+	for _, s := range subs {
+		s.Close()
+	}
+}
+
+//Subscribe
+//receive insert/update/delete events of the table after the writing txn is committed,
+//events are delivered in commit order, only txns committed with BormDb.Commit or the
+//non-tx write methods are published, events beyond the buffer of a slow subscriber are
+//dropped and counted by Subscription.Dropped
+func (bormDb *BormDb) Subscribe(row IRow, filter ChangeFilter, handler ChangeHandler) (*Subscription, error) {
+	if handler == nil {
+		return nil, ErrNilCallback
+	}
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return nil, err
+	}
+	return bormDb.changes.add(tableId, filter, handler), nil
+}
@@ -0,0 +1,199 @@
+package borm
+
+import (
+	"fmt"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/longbridgeapp/borm/pb"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Run("events after commit", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			err := db.CreateTable(&pb.Person{})
+			require.NoError(t, err)
+			events := make(chan *ChangeEvent, 10)
+			sub, err := db.Subscribe(&pb.Person{}, nil, func(event *ChangeEvent) {
+				events <- event
+			})
+			require.NoError(t, err)
+			defer sub.Close()
+
+			err = db.Insert(&pb.Person{Name: "jacky", Phone: "+861", Age: 30})
+			require.NoError(t, err)
+			err = db.Update(1, &pb.Person{Name: "jacky", Phone: "+861", Age: 31})
+			require.NoError(t, err)
+
+			tx := db.Begin(true)
+			err = db.TxDelete(tx, 1, &pb.Person{})
+			require.NoError(t, err)
+			db.Discard(tx)
+
+			err = db.Delete(1, &pb.Person{})
+			require.NoError(t, err)
+
+			event := <-events
+			require.Equal(t, ChangeInsert, event.Type)
+			require.Equal(t, uint64(1), event.RowId)
+			require.Nil(t, event.Before)
+			require.Equal(t, uint32(30), event.After.(*pb.Person).Age)
+
+			event = <-events
+			require.Equal(t, ChangeUpdate, event.Type)
+			require.Equal(t, uint32(30), event.Before.(*pb.Person).Age)
+			require.Equal(t, uint32(31), event.After.(*pb.Person).Age)
+
+			event = <-events
+			require.Equal(t, ChangeDelete, event.Type)
+			require.Equal(t, "Person", event.TableName)
+			require.Equal(t, uint32(31), event.Before.(*pb.Person).Age)
+			require.Nil(t, event.After)
+
+			select {
+			case event = <-events:
+				require.Fail(t, "unexpected event", event.Type.String())
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		runNewBorm(t, func(t *testing.T, db *BormDb) {
+			err := db.CreateTable(&pb.Person{})
+			require.NoError(t, err)
+			_, err = db.Subscribe(&pb.Order{}, nil, func(event *ChangeEvent) {})
+			require.ErrorIs(t, err, ErrTableNotFound)
+
+			events := make(chan *ChangeEvent, 10)
+			sub, err := db.Subscribe(&pb.Person{}, func(event *ChangeEvent) bool {
+				return event.After.(*pb.Person).Name == "jim"
+			}, func(event *ChangeEvent) {
+				events <- event
+			})
+			require.NoError(t, err)
+			defer sub.Close()
+
+			tx := db.Begin(true)
+			err = db.TxBatchInsert(tx, []IRow{
+				&pb.Person{Name: "jacky", Phone: "+861"},
+				&pb.Person{Name: "jim", Phone: "+862"},
+				&pb.Person{Name: "jim", Phone: "+863"},
+			})
+			require.NoError(t, err)
+			err = db.Commit(tx)
+			require.NoError(t, err)
+
+			require.Equal(t, "+862", (<-events).After.(*pb.Person).Phone)
+			require.Equal(t, "+863", (<-events).After.(*pb.Person).Phone)
+		})
+	})
+}
+
+func TestSubscribeSlowSubscriber(t *testing.T) {
+	t.Run("buffer size", func(t *testing.T) {
+		_, err := New(WithSubscribeBufferSize(0))
+		require.ErrorIs(t, err, ErrOptionIllegal)
+		_, err = New(WithSubscribeBufferSize(-1))
+		require.ErrorIs(t, err, ErrOptionIllegal)
+	})
+
+	t.Run("full buffer drops events", func(t *testing.T) {
+		db, err := New(WithSubscribeBufferSize(1))
+		require.NoError(t, err)
+		defer db.Close()
+		err = db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		block := make(chan struct{})
+		received := make(chan *ChangeEvent, 10)
+		sub, err := db.Subscribe(&pb.Person{}, nil, func(event *ChangeEvent) {
+			<-block
+			received <- event
+		})
+		require.NoError(t, err)
+		defer sub.Close()
+
+		//commits don't wait for the blocked handler
+		for i := 0; i < 5; i++ {
+			err = db.Insert(&pb.Person{Name: "jacky", Phone: fmt.Sprint(i)})
+			require.NoError(t, err)
+		}
+		close(block)
+		dropped := int(sub.Dropped())
+		require.GreaterOrEqual(t, dropped, 3)
+		for i := 0; i < 5-dropped; i++ {
+			<-received
+		}
+		select {
+		case event := <-received:
+			require.Fail(t, "unexpected event", event.Type.String())
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("handler writes", func(t *testing.T) {
+		db, err := New(WithSubscribeBufferSize(1))
+		require.NoError(t, err)
+		defer db.Close()
+		err = db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		err = db.CreateTable(&pb.Order{})
+		require.NoError(t, err)
+		sub, err := db.Subscribe(&pb.Person{}, nil, func(event *ChangeEvent) {
+			require.NoError(t, db.Insert(&pb.Order{Aaid: event.RowId}))
+		})
+		require.NoError(t, err)
+		defer sub.Close()
+		for i := 0; i < 20; i++ {
+			err = db.Insert(&pb.Person{Name: "jacky", Phone: fmt.Sprint(i)})
+			require.NoError(t, err)
+		}
+		//events may be dropped, but no commit deadlocks
+		require.Eventually(t, func() bool {
+			count, err := db.Count(&pb.Order{})
+			require.NoError(t, err)
+			return count == 20-sub.Dropped()
+		}, time.Second, 10*time.Millisecond)
+	})
+}
+
+func TestSubscribePending(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		sub, err := db.Subscribe(&pb.Person{}, nil, func(event *ChangeEvent) {})
+		require.NoError(t, err)
+		defer sub.Close()
+
+		pending := func() int {
+			n := 0
+			db.changes.pending.Range(func(k, v any) bool {
+				n++
+				return true
+			})
+			return n
+		}
+		func() {
+			//committed and discarded without BormDb
+			tx := db.Begin(true)
+			require.NoError(t, db.TxInsert(tx, &pb.Person{Name: "jacky", Phone: "+861"}))
+			require.NoError(t, tx.Commit())
+			tx = db.Begin(true)
+			require.NoError(t, db.TxInsert(tx, &pb.Person{Name: "jim", Phone: "+862"}))
+			tx.Discard()
+		}()
+		require.Eventually(t, func() bool {
+			runtime.GC()
+			return pending() == 0
+		}, time.Second, 10*time.Millisecond)
+
+		tx := db.Begin(true)
+		require.NoError(t, db.TxInsert(tx, &pb.Person{Name: "lucy", Phone: "+863"}))
+		require.Equal(t, 1, pending())
+		db.Discard(tx)
+		require.Equal(t, 0, pending())
+	})
+}
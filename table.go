@@ -1,12 +1,22 @@
 package borm
 
 import (
+	"bytes"
 	"reflect"
+	"sort"
 	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/longbridgeapp/borm/common"
 
 	badger "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
 )
 
+//IRow
+//a row of a table like the gogo protobuf generated types, plain structs are wrapped by StructRow
 type IRow interface {
 	Marshal() ([]byte, error)
 	Unmarshal([]byte) error
@@ -14,11 +24,137 @@ type IRow interface {
 	Clone() any
 }
 
+type tableOptions struct {
+	name           string
+	codec          Codec
+	ttl            time.Duration
+	idGenerator    IdGenerator
+	seqStart       uint64
+	seqBandwidth   uint64
+	gapFree        bool
+	foreignKeys    []foreignKeyDecl
+	checks         []checkDecl
+	pathIndexes    []pathIndexDecl
+	partialIndexes []partialIndexDecl
+}
+
+type partialIndexDecl struct {
+	name        string
+	fieldName   string
+	indexType   IndexType
+	whereField  string
+	whereValues []any
+}
+
+type pathIndexDecl struct {
+	path      string
+	indexType IndexType
+}
+
+type TableOption func(*tableOptions)
+
+//WithPartialIndex
+//add a unique or normal index named name over fieldName that only holds the rows whose
+//whereField is one of whereValues, e.g. the open orders of a counter,
+//queries use it when they also have an Eq condition on whereField with one of whereValues
+func WithPartialIndex(name, fieldName string, indexType IndexType, whereField string, whereValues ...any) TableOption {
+	return func(o *tableOptions) {
+		o.partialIndexes = append(o.partialIndexes, partialIndexDecl{
+			name:        name,
+			fieldName:   fieldName,
+			indexType:   indexType,
+			whereField:  whereField,
+			whereValues: whereValues,
+		})
+	}
+}
+
+//WithPathIndex
+//add a unique or normal index over a nested field path like `AccountProperties.MainCurrency`,
+//a path ending at a map indexes its keys and a repeated field indexes its elements
+func WithPathIndex(path string, indexType IndexType) TableOption {
+	return func(o *tableOptions) {
+		o.pathIndexes = append(o.pathIndexes, pathIndexDecl{path: path, indexType: indexType})
+	}
+}
+
+//WithTableName
+//the name of the table, overrides GetTableName of the row
+func WithTableName(name string) TableOption {
+	return func(o *tableOptions) {
+		o.name = name
+	}
+}
+
+//WithCodec
+//encode the rows of the table with codec instead of their own Marshal and Unmarshal
+func WithCodec(codec Codec) TableOption {
+	return func(o *tableOptions) {
+		o.codec = codec
+	}
+}
+
+//WithTableTTL
+//rows of the table and their index entries expire ttl after insert, rounded up to whole seconds
+func WithTableTTL(ttl time.Duration) TableOption {
+	return func(o *tableOptions) {
+		o.ttl = ttl
+	}
+}
+
+//WithIdGenerator
+//ids of inserted rows come from gen instead of the table sequence
+func WithIdGenerator(gen IdGenerator) TableOption {
+	return func(o *tableOptions) {
+		o.idGenerator = gen
+	}
+}
+
+//WithSequence
+//ids of the table sequence start at start and are leased bandwidth at a time, the unused
+//rest of a lease is returned on Close, the defaults are 1 and 1<<30
+func WithSequence(start, bandwidth uint64) TableOption {
+	return func(o *tableOptions) {
+		o.seqStart = start
+		o.seqBandwidth = bandwidth
+	}
+}
+
+//WithGapFreeIds
+//ids are allocated inside the inserting txn, so they increase by 1 without gaps even
+//when txns are discarded, concurrent inserts into the table conflict and retry, an Id
+//given to InsertWithId moves the next id past it
+func WithGapFreeIds() TableOption {
+	return func(o *tableOptions) {
+		o.gapFree = true
+	}
+}
+
 type TableManager struct {
-	tables    sync.Map
-	tableSeqs sync.Map
-	indexTags sync.Map
-	unionTags sync.Map
+	tables       sync.Map
+	tableSeqs    sync.Map
+	indexTags    sync.Map
+	unionTags    sync.Map
+	tableOptions sync.Map
+	//reflect.Type of the registered rows to their table names
+	rowTypes sync.Map
+	//table id to the *rowAccessor of its row type
+	rowAccessors sync.Map
+	//reflect.Type of the registered rows to the *rowAccessor of their table
+	typeAccessors sync.Map
+	//the *accessorCache of the row type looked up last, valid while dropSeq is unchanged
+	lastAccessor unsafe.Pointer
+	dropSeq      uint32
+	//table ids are never reused after DropTable
+	tableIdSeq uint32
+	//serialize copy-on-write updates of indexTags
+	indexLock sync.Mutex
+	//table id to the foreign keys it declares and to the foreign keys referencing it
+	foreignKeys    sync.Map
+	references     sync.Map
+	foreignKeyLock sync.Mutex
+	//table id to the []*fieldConstraint of its check rules
+	constraints sync.Map
 }
 
 func newTableManager() *TableManager {
@@ -27,9 +163,55 @@ func newTableManager() *TableManager {
 	t.indexTags = sync.Map{}
 	t.unionTags = sync.Map{}
 	t.tableSeqs = sync.Map{}
+	t.tableOptions = sync.Map{}
+	t.rowTypes = sync.Map{}
+	t.rowAccessors = sync.Map{}
+	t.typeAccessors = sync.Map{}
+	t.foreignKeys = sync.Map{}
+	t.references = sync.Map{}
+	t.constraints = sync.Map{}
 	return t
 }
 
+//GetTableName
+//the table of row, the name registered for its type or else its GetTableName
+func (t *TableManager) GetTableName(row IRow) string {
+	if v, ok := t.rowTypes.Load(reflect.TypeOf(row)); ok {
+		return v.(string)
+	}
+	return row.GetTableName()
+}
+
+func (t *TableManager) getCodec(tableId uint32) Codec {
+	codec := t.GetTableOptions(tableId).codec
+	if codec == nil {
+		return messageCodec{}
+	}
+	return codec
+}
+
+//Marshal
+//encode row with the codec of the table
+func (t *TableManager) Marshal(tableId uint32, row IRow) ([]byte, error) {
+	return t.getCodec(tableId).Marshal(row)
+}
+
+//Unmarshal
+//decode data into a new row of the type of tpl with the codec of the table
+func (t *TableManager) Unmarshal(tableId uint32, tpl IRow, data []byte) (IRow, error) {
+	row := cloneRow(tpl)
+	if err := t.getCodec(tableId).Unmarshal(data, row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
+
+//cloneRow
+//an empty row of the type of row
+func cloneRow(row IRow) IRow {
+	return row.Clone().(IRow)
+}
+
 func (t *TableManager) GetTableId(tableName string) (uint32, error) {
 	v, ok := t.tables.Load(tableName)
 	if !ok {
@@ -38,67 +220,373 @@ func (t *TableManager) GetTableId(tableName string) (uint32, error) {
 	return v.(uint32), nil
 }
 
-func (t *TableManager) CreateTable(tp IRow, db *badger.DB) error {
-	tableName := tp.GetTableName()
+func (t *TableManager) CreateTable(tp IRow, db *badger.DB, opts ...TableOption) error {
+	value := reflect.ValueOf(tp)
+	if value.Kind() != reflect.Ptr || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return ErrRowTypeIllegal
+	}
+	structValue := rowStruct(tp)
+	if structValue.Kind() != reflect.Struct {
+		return ErrRowTypeIllegal
+	}
+	tableOpts := &tableOptions{}
+	for _, opt := range opts {
+		opt(tableOpts)
+	}
+	if tableOpts.name == "" {
+		tableOpts.name = tp.GetTableName()
+	}
+	if tableOpts.name == "" {
+		return ErrTableNameEmpty
+	}
+	if err := checkRowType(tp, tableOpts); err != nil {
+		return err
+	}
+	if tableOpts.idGenerator != nil && (tableOpts.gapFree || tableOpts.seqStart != 0 || tableOpts.seqBandwidth != 0) {
+		return ErrIdGeneratorIllegal
+	}
+	if tableOpts.seqStart == 0 {
+		tableOpts.seqStart = 1
+	}
+	if tableOpts.seqBandwidth == 0 {
+		tableOpts.seqBandwidth = 1 << 30
+	}
+	tableName := tableOpts.name
 	if _, err := t.GetTableId(tableName); err == nil {
 		return ErrTableRepeat
 	}
-	value := reflect.ValueOf(tp)
+	if _, ok := t.rowTypes.Load(value.Type()); ok {
+		return ErrTableRepeat
+	}
 	tapMap := map[uint32]*tag{}
-	unionIndexSlice := []uint32{}
+	unionIndexes := []*unionIndex{}
 	//init index
-	for i := 0; i < value.Elem().NumField(); i++ {
+	for i := 0; i < structValue.NumField(); i++ {
 		//check first field must be pk field
 		if i == 0 {
-			if value.Elem().Type().Field(i).Name != "Id" {
+			if structValue.Type().Field(i).Name != "Id" {
 				return ErrRowIdIllegal
 			}
-			if value.Elem().Type().Field(i).Type != reflect.TypeOf(uint64(0)) {
+			if structValue.Type().Field(i).Type != reflect.TypeOf(uint64(0)) {
 				return ErrRowIdIllegal
 			}
 		}
-		tagStr := value.Elem().Type().Field(i).Tag.Get("idx")
+		tagStr := structValue.Type().Field(i).Tag.Get("idx")
 		if tagStr == "" || tagStr == "-" {
 			continue
 		}
-		idxType, err := getIndex(tagStr)
+		fieldDecl, err := parseIndexTag(tagStr)
 		if err != nil {
 			return err
 		}
-		offset := value.Elem().Type().Field(i).Offset
-
-		tag, err := GetTag(value.Elem().Type().Field(i).Name, value.Elem().Field(i).Interface(), offset, idxType)
+		tag, err := GetTag(structValue.Type().Field(i).Name, structValue.Field(i).Interface(), i, fieldDecl.indexType)
 		if err != nil {
 			return err
 		}
+		if fieldDecl.caseInsensitive {
+			if tag.fieldType != String {
+				return ErrIdxNotSupport
+			}
+			tag.caseInsensitive = true
+		}
+		if tag.CheckIsFulltext() && tag.fieldType != String {
+			return ErrIdxNotSupport
+		}
 		tapMap[uint32(i)] = tag
 
-		if checkIsUnion(tagStr) {
-			unionIndexSlice = append(unionIndexSlice, uint32(i))
+		for _, decl := range fieldDecl.unions {
+			unionIndexes, err = addUnionField(unionIndexes, decl, uint32(i))
+			if err != nil {
+				return err
+			}
 		}
 	}
-	tableId := uint32(0)
-	t.tables.Range(func(key, value any) bool {
-		tableId++
-		return true
-	})
+	for i, decl := range tableOpts.pathIndexes {
+		if decl.indexType != UNIQUE && decl.indexType != NORMAL {
+			return ErrIdxNotSupport
+		}
+		tag, err := newPathTag(structValue.Type(), decl.path, decl.indexType)
+		if err != nil {
+			return err
+		}
+		for _, other := range tapMap {
+			if other.name() == tag.name() {
+				return ErrIdxRepeat
+			}
+		}
+		tapMap[pathIdxBase+uint32(i)] = tag
+	}
+	for i, decl := range tableOpts.partialIndexes {
+		if decl.indexType != UNIQUE && decl.indexType != NORMAL {
+			return ErrIdxNotSupport
+		}
+		tag, _, err := newIndexTag(value.Type(), decl.fieldName, decl.indexType)
+		if err != nil {
+			return err
+		}
+		tag.indexName = decl.name
+		tag.predicate, err = newIndexPredicate(value.Type(), decl.whereField, decl.whereValues)
+		if err != nil {
+			return err
+		}
+		for _, other := range tapMap {
+			if other.name() == tag.name() {
+				return ErrIdxRepeat
+			}
+		}
+		tapMap[pathIdxBase+uint32(len(tableOpts.pathIndexes)+i)] = tag
+	}
+	constraints, err := newFieldConstraints(value.Type(), tableOpts.checks)
+	if err != nil {
+		return err
+	}
+	fks := make([]*foreignKey, 0, len(tableOpts.foreignKeys))
+	for _, decl := range tableOpts.foreignKeys {
+		fk, err := t.newForeignKey(value.Type(), decl)
+		if err != nil {
+			return err
+		}
+		fks = append(fks, fk)
+	}
+	tableId := atomic.AddUint32(&t.tableIdSeq, 1) - 1
 	t.tables.Store(tableName, tableId)
+	//the registered name wins over GetTableName of the row
+	if tableName != tp.GetTableName() {
+		t.rowTypes.Store(value.Type(), tableName)
+	}
+	accessor := newRowAccessor(tableId, value.Type())
+	t.rowAccessors.Store(tableId, accessor)
+	t.typeAccessors.Store(value.Type(), accessor)
+	t.addForeignKeys(tableId, fks)
+	t.constraints.Store(tableId, constraints)
 	t.indexTags.Store(tableId, tapMap)
-	t.unionTags.Store(tableId, unionIndexSlice)
+	t.unionTags.Store(tableId, unionIndexes)
+	t.tableOptions.Store(tableId, tableOpts)
+	if tableOpts.idGenerator != nil {
+		return nil
+	}
 
-	seq, err := db.GetSequence(encodeSeqKey(tableId), 1<<30)
+	//the sequence key holds the next id, a persistent table keeps its stored value
+	err = db.Update(func(txn *badger.Txn) error {
+		_, err := txn.Get(encodeSeqKey(tableId))
+		if err != badger.ErrKeyNotFound {
+			return err
+		}
+		return txn.Set(encodeSeqKey(tableId), common.EncodedFromUInt64(tableOpts.seqStart))
+	})
+	if err != nil || tableOpts.gapFree {
+		return err
+	}
+	seq, err := db.GetSequence(encodeSeqKey(tableId), tableOpts.seqBandwidth)
 	if err != nil {
 		return err
 	}
-	seq.Next()
 	t.tableSeqs.Store(tableId, seq)
 	return nil
 }
 
+//checkRowType
+//rows of the table are cloned, encoded and named by their own methods, so check up front that
+//Clone returns the same type, GetTableName is stable and a zero row survives the codec
+func checkRowType(tp IRow, tableOpts *tableOptions) error {
+	rowType := reflect.TypeOf(tp)
+	clone := tp.Clone()
+	if reflect.TypeOf(clone) != rowType {
+		return errors.Wrapf(ErrRowCloneIllegal, "Clone of %v returns %T", rowType, clone)
+	}
+	if clone.(IRow).GetTableName() != tp.GetTableName() || tp.GetTableName() != tp.GetTableName() {
+		return errors.Wrapf(ErrTableNameUnstable, "GetTableName of %v", rowType)
+	}
+	codec := tableOpts.codec
+	if codec == nil {
+		codec = messageCodec{}
+	}
+	bs, err := codec.Marshal(cloneRow(tp))
+	if err != nil {
+		return errors.Wrapf(ErrRowCodecIllegal, "marshal zero %v: %v", rowType, err)
+	}
+	decoded := cloneRow(tp)
+	if err := codec.Unmarshal(bs, decoded); err != nil {
+		return errors.Wrapf(ErrRowCodecIllegal, "unmarshal zero %v: %v", rowType, err)
+	}
+	again, err := codec.Marshal(decoded)
+	if err != nil {
+		return errors.Wrapf(ErrRowCodecIllegal, "marshal decoded %v: %v", rowType, err)
+	}
+	if !bytes.Equal(bs, again) {
+		return errors.Wrapf(ErrRowCodecIllegal, "zero %v changes after a round trip", rowType)
+	}
+	return nil
+}
+
+func addUnionField(unionIndexes []*unionIndex, decl unionDecl, fieldIdx uint32) ([]*unionIndex, error) {
+	for _, union := range unionIndexes {
+		if union.name == decl.name {
+			if union.unique != decl.unique {
+				return nil, ErrIdxNotSupport
+			}
+			union.fields = append(union.fields, fieldIdx)
+			return unionIndexes, nil
+		}
+	}
+	return append(unionIndexes, &unionIndex{
+		name:   decl.name,
+		fields: []uint32{fieldIdx},
+		unique: decl.unique,
+	}), nil
+}
+
+func (t *TableManager) DropTable(tableName string) error {
+	v, ok := t.tables.Load(tableName)
+	if !ok {
+		return ErrTableNotFound
+	}
+	tableId := v.(uint32)
+	if err := t.removeForeignKeys(tableId); err != nil {
+		return err
+	}
+	t.tables.Delete(tableName)
+	t.rowTypes.Range(func(k, v any) bool {
+		if v.(string) == tableName {
+			t.rowTypes.Delete(k)
+		}
+		return true
+	})
+	if accessor, ok := t.rowAccessors.LoadAndDelete(tableId); ok {
+		t.typeAccessors.Delete(accessor.(*rowAccessor).rowType)
+		atomic.AddUint32(&t.dropSeq, 1)
+	}
+	t.constraints.Delete(tableId)
+	t.indexTags.Delete(tableId)
+	t.unionTags.Delete(tableId)
+	t.tableOptions.Delete(tableId)
+	if seq, ok := t.tableSeqs.LoadAndDelete(tableId); ok {
+		return seq.(*badger.Sequence).Release()
+	}
+	return nil
+}
+
+func (t *TableManager) RenameTable(tableName, newTableName string) error {
+	v, ok := t.tables.Load(tableName)
+	if !ok {
+		return ErrTableNotFound
+	}
+	if _, loaded := t.tables.LoadOrStore(newTableName, v); loaded {
+		return ErrTableRepeat
+	}
+	t.tables.Delete(tableName)
+	//rows follow their table instead of going by GetTableName
+	accessor, err := t.GetRowAccessor(v.(uint32))
+	if err != nil {
+		return err
+	}
+	t.rowTypes.Store(accessor.rowType, newTableName)
+	return nil
+}
+
+//GetTables
+//table name by id, ordered by id
+func (t *TableManager) GetTables() ([]uint32, map[uint32]string) {
+	ids := []uint32{}
+	names := map[uint32]string{}
+	t.tables.Range(func(key, value any) bool {
+		ids = append(ids, value.(uint32))
+		names[value.(uint32)] = key.(string)
+		return true
+	})
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids, names
+}
+
+func (t *TableManager) GetIndexInfos(tableId uint32) []IndexInfo {
+	tags := t.GetIndexTags(tableId)
+	fieldIdxes := make([]int, 0, len(tags))
+	for fieldIdx := range tags {
+		fieldIdxes = append(fieldIdxes, int(fieldIdx))
+	}
+	sort.Ints(fieldIdxes)
+	infos := []IndexInfo{}
+	for _, fieldIdx := range fieldIdxes {
+		tag := tags[uint32(fieldIdx)]
+		infos = append(infos, IndexInfo{
+			Name:     tag.name(),
+			Type:     tag.indexType,
+			Fields:   []string{tag.fieldName},
+			Unique:   tag.CheckIsUnique(),
+			Building: !tag.CheckIsReady(),
+			Partial:  tag.predicate != nil,
+		})
+	}
+	for _, union := range t.GetUnionIndexes(tableId) {
+		info := IndexInfo{Name: union.name, Type: UNION, Unique: union.unique}
+		for _, fieldIdx := range union.fields {
+			info.Fields = append(info.Fields, tags[fieldIdx].fieldName)
+		}
+		infos = append(infos, info)
+	}
+	return infos
+}
+
+func (t *TableManager) GetTableOptions(tableId uint32) *tableOptions {
+	v, ok := t.tableOptions.Load(tableId)
+	if !ok {
+		return &tableOptions{}
+	}
+	return v.(*tableOptions)
+}
+
+//GetRowAccessor
+//the field accessor of the rows of the table
+func (t *TableManager) GetRowAccessor(tableId uint32) (*rowAccessor, error) {
+	v, ok := t.rowAccessors.Load(tableId)
+	if !ok {
+		return nil, ErrTableNotFound
+	}
+	return v.(*rowAccessor), nil
+}
+
+type accessorCache struct {
+	typeWord unsafe.Pointer
+	accessor *rowAccessor
+	dropSeq  uint32
+}
+
+//lookupRowAccessor
+//the accessor of the table of row, found by the row type, a row of an unregistered
+//type gets the accessor of the table it names to report the mismatch
+func (t *TableManager) lookupRowAccessor(row IRow) (*rowAccessor, error) {
+	typeWord, _ := interfaceWords(row)
+	dropSeq := atomic.LoadUint32(&t.dropSeq)
+	if last := (*accessorCache)(atomic.LoadPointer(&t.lastAccessor)); last != nil && last.typeWord == typeWord && last.dropSeq == dropSeq {
+		return last.accessor, nil
+	}
+	if v, ok := t.typeAccessors.Load(reflect.TypeOf(row)); ok {
+		accessor := v.(*rowAccessor)
+		atomic.StorePointer(&t.lastAccessor, unsafe.Pointer(&accessorCache{typeWord: typeWord, accessor: accessor, dropSeq: dropSeq}))
+		return accessor, nil
+	}
+	tableId, err := t.GetTableId(t.GetTableName(row))
+	if err != nil {
+		return nil, err
+	}
+	return t.GetRowAccessor(tableId)
+}
+
+//CheckRow
+//whether row is a pointer to the struct registered for the table
+func (t *TableManager) CheckRow(tableId uint32, row IRow) error {
+	accessor, err := t.GetRowAccessor(tableId)
+	if err != nil {
+		return err
+	}
+	_, err = accessor.check(row)
+	return err
+}
+
 func (t *TableManager) GetIndexTag(tableId uint32, fieldName string) (*tag, error) {
 	tags := t.GetIndexTags(tableId)
 	for _, tag := range tags {
-		if tag.fieldName == fieldName {
+		if tag.fieldName == fieldName && tag.predicate == nil {
 			return tag, nil
 		}
 	}
@@ -114,19 +602,103 @@ func (t *TableManager) GetIndexTags(tableId uint32) map[uint32]*tag {
 	return v.(map[uint32]*tag)
 }
 
-func (t *TableManager) GetUnionTags(tableId uint32) []uint32 {
+func (t *TableManager) GetUnionIndexes(tableId uint32) []*unionIndex {
 	v, ok := t.unionTags.Load(tableId)
 	if !ok {
-		return []uint32{}
+		return []*unionIndex{}
+	}
+	return v.([]*unionIndex)
+}
+
+//GetUnionIndex
+//the unique or multi union index over exactly the given fields
+func (t *TableManager) GetUnionIndex(tableId uint32, fieldIdxes []uint32, unique bool) (*unionIndex, error) {
+	for _, union := range t.GetUnionIndexes(tableId) {
+		if union.unique != unique || len(union.fields) != len(fieldIdxes) {
+			continue
+		}
+		matched := 0
+		for _, fieldIdx := range union.fields {
+			for _, idx := range fieldIdxes {
+				if idx == fieldIdx {
+					matched++
+					break
+				}
+			}
+		}
+		if matched == len(union.fields) {
+			return union, nil
+		}
+	}
+	return nil, ErrIdxNotSupport
+}
+
+func (t *TableManager) IsUnionField(tableId, fieldIdx uint32) bool {
+	for _, union := range t.GetUnionIndexes(tableId) {
+		for _, idx := range union.fields {
+			if idx == fieldIdx {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (t *TableManager) AddIndexTag(tableId, fieldIdx uint32, indexTag *tag) error {
+	t.indexLock.Lock()
+	defer t.indexLock.Unlock()
+	return t.addIndexTag(tableId, fieldIdx, indexTag)
+}
+
+//AddPathIndexTag
+//add the tag of a path or partial index under the next free index id after the struct fields
+func (t *TableManager) AddPathIndexTag(tableId uint32, indexTag *tag) (uint32, error) {
+	t.indexLock.Lock()
+	defer t.indexLock.Unlock()
+	fieldIdx := pathIdxBase
+	for idx := range t.GetIndexTags(tableId) {
+		if idx >= fieldIdx {
+			fieldIdx = idx + 1
+		}
 	}
-	return v.([]uint32)
+	return fieldIdx, t.addIndexTag(tableId, fieldIdx, indexTag)
+}
+
+func (t *TableManager) addIndexTag(tableId, fieldIdx uint32, indexTag *tag) error {
+	tags := t.GetIndexTags(tableId)
+	if _, ok := tags[fieldIdx]; ok {
+		return ErrIdxRepeat
+	}
+	newTags := make(map[uint32]*tag, len(tags)+1)
+	for k, v := range tags {
+		if v.name() == indexTag.name() {
+			return ErrIdxRepeat
+		}
+		newTags[k] = v
+	}
+	newTags[fieldIdx] = indexTag
+	t.indexTags.Store(tableId, newTags)
+	return nil
+}
+
+func (t *TableManager) RemoveIndexTag(tableId, fieldIdx uint32) {
+	t.indexLock.Lock()
+	defer t.indexLock.Unlock()
+	tags := t.GetIndexTags(tableId)
+	newTags := make(map[uint32]*tag, len(tags))
+	for k, v := range tags {
+		if k != fieldIdx {
+			newTags[k] = v
+		}
+	}
+	t.indexTags.Store(tableId, newTags)
 }
 
 func (t *TableManager) GetNormalIdx(tableId uint32, fieldName string) (uint32, error) {
 	tags := t.GetIndexTags(tableId)
 	for idx, tag := range tags {
-		if tag.fieldName == fieldName {
-			if !tag.CheckIsNormal() {
+		if tag.fieldName == fieldName && tag.predicate == nil {
+			if !tag.CheckIsNormal() || !tag.CheckIsReady() {
 				return 0, ErrIdxNotSupport
 			}
 			return idx, nil
@@ -138,8 +710,8 @@ func (t *TableManager) GetNormalIdx(tableId uint32, fieldName string) (uint32, e
 func (t *TableManager) GetUniqueIdx(tableId uint32, fieldName string) (uint32, error) {
 	tags := t.GetIndexTags(tableId)
 	for idx, tag := range tags {
-		if tag.fieldName == fieldName {
-			if !tag.CheckIsUnique() {
+		if tag.fieldName == fieldName && tag.predicate == nil {
+			if !tag.CheckIsUnique() || !tag.CheckIsReady() {
 				return 0, ErrIdxNotSupport
 			}
 			return idx, nil
@@ -148,6 +720,31 @@ func (t *TableManager) GetUniqueIdx(tableId uint32, fieldName string) (uint32, e
 	return 0, ErrIdxNotSupport
 }
 
+func (t *TableManager) GetFulltextIdx(tableId uint32, fieldName string) (uint32, error) {
+	tags := t.GetIndexTags(tableId)
+	for idx, tag := range tags {
+		if tag.fieldName == fieldName && tag.CheckIsFulltext() && tag.CheckIsReady() {
+			return idx, nil
+		}
+	}
+	return 0, ErrIdxNotSupport
+}
+
+//GetPartialIdx
+//a ready partial index over fieldName whose predicate is implied by the eq conditions
+func (t *TableManager) GetPartialIdx(tableId uint32, fieldName string, eqConditions map[string]any) (uint32, *tag, bool) {
+	for idx, tag := range t.GetIndexTags(tableId) {
+		if tag.predicate == nil || tag.fieldName != fieldName || !tag.CheckIsReady() {
+			continue
+		}
+		val, ok := eqConditions[tag.predicate.tag.fieldName]
+		if ok && tag.predicate.implied(val) {
+			return idx, tag, true
+		}
+	}
+	return 0, nil, false
+}
+
 func (t *TableManager) GetUnionTagsByFieldConditions(tableId uint32, conditionsMap map[string]any) map[uint32]any {
 	v, ok := t.indexTags.Load(tableId)
 	if !ok {
@@ -155,6 +752,9 @@ func (t *TableManager) GetUnionTagsByFieldConditions(tableId uint32, conditionsM
 	}
 	resultMap := map[uint32]any{}
 	for id, tag := range v.(map[uint32]*tag) {
+		if tag.predicate != nil {
+			continue
+		}
 		if v, ok := conditionsMap[tag.fieldName]; ok {
 			resultMap[id] = v
 		}
@@ -163,7 +763,66 @@ func (t *TableManager) GetUnionTagsByFieldConditions(tableId uint32, conditionsM
 
 }
 
+//NextWithTxn
+//like Next, but a gap-free table takes the id from its sequence key within txn
+func (t *TableManager) NextWithTxn(txn *badger.Txn, tableId uint32) (uint64, error) {
+	if !t.GetTableOptions(tableId).gapFree {
+		return t.Next(tableId)
+	}
+	next, err := readSeqWithTxn(txn, tableId)
+	if err != nil {
+		return 0, err
+	}
+	return next, txn.Set(encodeSeqKey(tableId), common.EncodedFromUInt64(next+1))
+}
+
+//SkipWithTxn
+//move the sequence key of a gap-free table past an id inserted by the caller, so its ids keep
+//increasing, the leased sequences skip taken ids on Next instead
+func (t *TableManager) SkipWithTxn(txn *badger.Txn, tableId uint32, id uint64) error {
+	if !t.GetTableOptions(tableId).gapFree {
+		return nil
+	}
+	next, err := readSeqWithTxn(txn, tableId)
+	if err != nil || id < next {
+		return err
+	}
+	return txn.Set(encodeSeqKey(tableId), common.EncodedFromUInt64(id+1))
+}
+
+func readSeqWithTxn(txn *badger.Txn, tableId uint32) (uint64, error) {
+	item, err := txn.Get(encodeSeqKey(tableId))
+	if err != nil {
+		return 0, err
+	}
+	next := uint64(0)
+	err = item.Value(func(val []byte) error {
+		next = common.DecodedToUInt64(val)
+		return nil
+	})
+	return next, err
+}
+
+//Close
+//release the leases of the table sequences, the unused ids are handed out after reopening
+func (t *TableManager) Close() error {
+	var err error
+	t.tableSeqs.Range(func(k, v any) bool {
+		t.tableSeqs.Delete(k)
+		if releaseErr := v.(*badger.Sequence).Release(); releaseErr != nil && err == nil {
+			err = releaseErr
+		}
+		return true
+	})
+	return err
+}
+
+//Next
+//the id of the next inserted row from the generator or the sequence of the table
 func (t *TableManager) Next(tableId uint32) (uint64, error) {
+	if gen := t.GetTableOptions(tableId).idGenerator; gen != nil {
+		return gen.Next()
+	}
 	v, ok := t.tableSeqs.Load(tableId)
 	if !ok {
 		return 0, ErrTableNotFound
@@ -0,0 +1,65 @@
+package borm
+
+import (
+	"sync"
+
+	badger "github.com/dgraph-io/badger/v3"
+)
+
+//TriggerFunc
+//before is nil on insert and after is nil on delete,
+//a returned error aborts the writing txn
+type TriggerFunc func(tx *badger.Txn, before, after IRow) error
+
+type triggerManager struct {
+	sync.RWMutex
+	triggers map[uint32][]TriggerFunc
+}
+
+func newTriggerManager() *triggerManager {
+	return &triggerManager{
+		triggers: map[uint32][]TriggerFunc{},
+	}
+}
+
+func (m *triggerManager) add(tableId uint32, fn TriggerFunc) {
+	m.Lock()
+	defer m.Unlock()
+	m.triggers[tableId] = append(m.triggers[tableId], fn)
+}
+
+func (m *triggerManager) remove(tableId uint32) {
+	m.Lock()
+	defer m.Unlock()
+	delete(m.triggers, tableId)
+}
+
+func (m *triggerManager) get(tableId uint32) []TriggerFunc {
+	m.RLock()
+	defer m.RUnlock()
+	return m.triggers[tableId]
+}
+
+//OnChange
+//register a trigger that runs inside the txn writing the table, so changes made
+//by the trigger commit or abort together with the source row
+func (bormDb *BormDb) OnChange(row IRow, fn TriggerFunc) error {
+	if fn == nil {
+		return ErrNilCallback
+	}
+	tableId, err := bormDb.tableManager.GetTableId(bormDb.tableManager.GetTableName(row))
+	if err != nil {
+		return err
+	}
+	bormDb.triggers.add(tableId, fn)
+	return nil
+}
+
+func (bormDb *BormDb) fireTriggers(tx *badger.Txn, tableId uint32, before, after IRow) error {
+	for _, fn := range bormDb.triggers.get(tableId) {
+		if err := fn(tx, before, after); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package borm
+
+import (
+	"github.com/longbridgeapp/borm/typed"
+)
+
+//Where
+//WithAnd(t) with all predicates applied, the predicates come from the typed fields
+//generated by protoc-gen-borm
+func Where[T IRow](t T, predicates ...typed.Predicate[T]) ICompoundConditions[T] {
+	condition := WithAnd(t)
+	for _, predicate := range predicates {
+		condition = applyPredicate(condition, predicate)
+	}
+	return condition
+}
+
+//applyPredicate
+//add the condition of predicate to c, a predicate not built by the typed fields
+//makes the query fail with ErrQueryInvalid
+func applyPredicate[T IRow](c ICompoundConditions[T], predicate typed.Predicate[T]) ICompoundConditions[T] {
+	if predicate.Op != typed.In && len(predicate.Values) != 1 {
+		c.invalidate()
+		return c
+	}
+	switch predicate.Op {
+	case typed.Eq:
+		return c.Eq(predicate.Field, predicate.Values[0])
+	case typed.In:
+		values := make([][]any, 0, len(predicate.Values))
+		for _, val := range predicate.Values {
+			values = append(values, []any{val})
+		}
+		return c.In([]string{predicate.Field}, values)
+	}
+	val, ok := predicate.Values[0].(string)
+	switch {
+	case ok && predicate.Op == typed.StartsWith:
+		return c.StartsWith(predicate.Field, val)
+	case ok && predicate.Op == typed.Like:
+		return c.Like(predicate.Field, val)
+	case ok && predicate.Op == typed.Match:
+		return c.Match(predicate.Field, val)
+	}
+	c.invalidate()
+	return c
+}
@@ -0,0 +1,72 @@
+//typed
+//the typed query builders generated by protoc-gen-borm, borm.Where applies them, the
+//package does not import borm so generated message packages can use it without a cycle
+package typed
+
+//Op
+//the condition a Predicate stands for
+type Op int
+
+const (
+	Eq Op = iota
+	In
+	StartsWith
+	Like
+	Match
+)
+
+//Predicate
+//a typed condition on the rows of T, Values holds one value except for In
+type Predicate[T any] struct {
+	Op     Op
+	Field  string
+	Values []any
+}
+
+//Field
+//a field of the row type T holding values of type V
+type Field[T any, V any] struct {
+	name string
+}
+
+func NewField[T any, V any](name string) Field[T, V] {
+	return Field[T, V]{name: name}
+}
+
+func (f Field[T, V]) Name() string {
+	return f.name
+}
+
+func (f Field[T, V]) Eq(val V) Predicate[T] {
+	return Predicate[T]{Op: Eq, Field: f.name, Values: []any{val}}
+}
+
+func (f Field[T, V]) In(vals ...V) Predicate[T] {
+	values := make([]any, 0, len(vals))
+	for _, val := range vals {
+		values = append(values, val)
+	}
+	return Predicate[T]{Op: In, Field: f.name, Values: values}
+}
+
+//StringField
+//a string field, adds the prefix and fulltext conditions
+type StringField[T any] struct {
+	Field[T, string]
+}
+
+func NewStringField[T any](name string) StringField[T] {
+	return StringField[T]{Field: NewField[T, string](name)}
+}
+
+func (f StringField[T]) StartsWith(prefix string) Predicate[T] {
+	return Predicate[T]{Op: StartsWith, Field: f.name, Values: []any{prefix}}
+}
+
+func (f StringField[T]) Like(pattern string) Predicate[T] {
+	return Predicate[T]{Op: Like, Field: f.name, Values: []any{pattern}}
+}
+
+func (f StringField[T]) Match(query string) Predicate[T] {
+	return Predicate[T]{Op: Match, Field: f.name, Values: []any{query}}
+}
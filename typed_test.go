@@ -0,0 +1,53 @@
+package borm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/longbridgeapp/borm/pb"
+	"github.com/longbridgeapp/borm/typed"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTypedQuery(t *testing.T) {
+	runNewBorm(t, func(t *testing.T, db *BormDb) {
+		err := db.CreateTable(&pb.Person{})
+		require.NoError(t, err)
+		for i := 0; i < 6; i++ {
+			err = db.Insert(&pb.Person{Name: fmt.Sprintf("jacky%d", i%2), Phone: fmt.Sprintf("+86%d", i), Age: uint32(20 + i)})
+			require.NoError(t, err)
+		}
+		age := pb.PersonQ.Age
+		require.Equal(t, "Age", age.Name())
+
+		results, err := Find(db, Where(&pb.Person{}, pb.PersonQ.Name.Eq("jacky1"), age.In(21, 23, 24)).SortBy(true, age.Name()))
+		require.NoError(t, err)
+		require.Equal(t, 2, len(results))
+		require.Equal(t, uint32(23), results[0].Age)
+		count, err := Count(db, Where(&pb.Person{}, pb.PersonQ.Phone.StartsWith("+86"), pb.PersonQ.Name.Like("jacky0")))
+		require.NoError(t, err)
+		require.Equal(t, 3, count)
+		//a predicate not built by the typed fields fails the query
+		_, err = Find(db, Where(&pb.Person{}, typed.Predicate[*pb.Person]{Op: typed.StartsWith, Field: pb.PersonFieldName}))
+		require.ErrorIs(t, err, ErrQueryInvalid)
+		_, err = Find(db, Where(&pb.Person{}, typed.Predicate[*pb.Person]{Op: typed.Like, Field: pb.PersonFieldName, Values: []any{1}}))
+		require.ErrorIs(t, err, ErrQueryInvalid)
+
+		err = db.CreateTable(&pb.Order{})
+		require.NoError(t, err)
+		err = db.Insert(&pb.Order{AccountChannel: "lb", Aaid: 1, OrderId: "1", CounterId: "ST/HK/700"})
+		require.NoError(t, err)
+		err = db.Insert(&pb.Order{AccountChannel: "lb_sg", Aaid: 1, OrderId: "2", CounterId: "ST/US/AAPL"})
+		require.NoError(t, err)
+		orders, err := Find(db, Where(&pb.Order{}, pb.OrderQ.AccountChannel.Eq("lb"), pb.OrderQ.Aaid.Eq(1)))
+		require.NoError(t, err)
+		require.Len(t, orders, 1)
+		require.Equal(t, "1", orders[0].OrderId)
+		count, err = Count(db, Where(&pb.Order{}, pb.OrderQ.CounterId.In("ST/HK/700", "ST/US/AAPL")))
+		require.NoError(t, err)
+		require.Equal(t, 2, count)
+		//the generated Clone returns the own type
+		require.IsType(t, &pb.IllegalPerson_2{}, (&pb.IllegalPerson_2{}).Clone())
+	})
+}